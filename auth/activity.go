@@ -0,0 +1,102 @@
+// Package auth provides authentication and authorization functionality,
+// including the last-activity tracking that server-side idle timeout
+// relies on.
+//
+// JwtMiddleware records each request in memory with TouchSession; the
+// flusher started by StartActivityFlusher writes the latest time per session
+// to session_data.last_activity in one batched UPDATE every
+// SESSION_ACTIVITY_FLUSH, so a busy session costs one write per interval,
+// not one per request.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package auth
+
+import (
+	"Hrmodule/config"
+	databaselogin "Hrmodule/database/login"
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// activityTracker buffers the last request time of each session.
+type activityTracker struct {
+	mu       sync.Mutex
+	interval time.Duration
+	pending  map[string]time.Time
+	flush    func(lastSeen map[string]time.Time) error
+}
+
+var activity = &activityTracker{
+	interval: 30 * time.Second,
+	pending:  make(map[string]time.Time),
+	flush:    databaselogin.TouchSessions,
+}
+
+// configureActivity applies SESSION_ACTIVITY_FLUSH.
+func configureActivity(cfg *config.Config) {
+	activity.interval = cfg.Sessions.ActivityFlushTime
+}
+
+// TouchSession records activity on sessionId now.
+func TouchSession(sessionId string) {
+	activity.mu.Lock()
+	activity.pending[sessionId] = time.Now()
+	activity.mu.Unlock()
+}
+
+// FlushSessionActivity writes the buffered activity to session_data. The
+// reaper calls it before looking for idle sessions. On failure the entries
+// are kept for the next flush unless newer ones arrived meanwhile.
+func FlushSessionActivity() error {
+	activity.mu.Lock()
+	batch := activity.pending
+	activity.pending = make(map[string]time.Time, len(batch))
+	activity.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := activity.flush(batch); err != nil {
+		activity.mu.Lock()
+		for id, t := range batch {
+			if _, newer := activity.pending[id]; !newer {
+				activity.pending[id] = t
+			}
+		}
+		activity.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// StartActivityFlusher flushes the buffered activity every
+// SESSION_ACTIVITY_FLUSH until ctx is done, then flushes once more.
+func StartActivityFlusher(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(activity.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := FlushSessionActivity(); err != nil {
+					log.Printf("Session activity flush failed: %v", err)
+				}
+			case <-ctx.Done():
+				if err := FlushSessionActivity(); err != nil {
+					log.Printf("Session activity flush failed: %v", err)
+				}
+				return
+			}
+		}
+	}()
+}
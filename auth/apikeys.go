@@ -0,0 +1,236 @@
+// Package auth provides authentication and authorization functionality,
+// including validation of the API key ("token") sent with every request.
+//
+// A key belongs to a vendor. A request passes when the key is active and
+// not expired, the vendor is active within its validity period, the API is
+// granted to the vendor and active within both its own and the grant's
+// validity period, and the client address is in one of the vendor's
+// allowlist entries (single addresses or CIDR ranges). Failures are answered
+// with 401 for key problems and 403 for everything else.
+//
+// Keys are looked up by their SHA-256 hash and cached for API_KEY_CACHE_TTL,
+// including unknown keys, so a burst of requests costs one lookup. The admin
+// key endpoints call InvalidateAPIKeys; other instances see the change once
+// their cache entry expires.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package auth
+
+import (
+	"Hrmodule/config"
+	databasecommon "Hrmodule/database/common"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status messages of API validation. They keep the names the
+// API_Validation_New procedure used, which clients already handle.
+const (
+	APIStatusSuccess         = "Success"
+	APIStatusInvalidKey      = "Invalid_Key"
+	APIStatusExpiredKey      = "Expired_Key"
+	APIStatusInvalidAPIName  = "Invalid_APIName"
+	APIStatusInactiveAPIName = "Inactive_APIName"
+	APIStatusInactiveVendor  = "Inactive_Vendor"
+	APIStatusInvalidIP       = "Invalid_IPAddress"
+)
+
+// apiKeyCacheMaxEntries bounds the cache; expired entries are swept when it is reached.
+const apiKeyCacheMaxEntries = 10000
+
+// apiKeyEntry is the cached result of one key lookup; access is nil for an unknown key.
+type apiKeyEntry struct {
+	access   *databasecommon.APIKeyAccess
+	networks []netip.Prefix
+	loadedAt time.Time
+}
+
+// apiKeyCache caches key lookups by key hash. The client certificates of
+// the mutual-TLS listener use a second instance keyed by fingerprint.
+type apiKeyCache struct {
+	mu       sync.RWMutex
+	ttl      time.Duration
+	entries  map[string]apiKeyEntry
+	lookup   func(keyHash string) (*databasecommon.APIKeyAccess, error)
+	notFound error // returned by lookup for an unknown key; cached as such
+}
+
+var apiKeys = &apiKeyCache{
+	ttl:      time.Minute,
+	entries:  make(map[string]apiKeyEntry),
+	lookup:   databasecommon.APIKeyByHash,
+	notFound: databasecommon.ErrAPIKeyNotFound,
+}
+
+// configureAPIKeys applies API_KEY_CACHE_TTL to the key and certificate caches.
+func configureAPIKeys(cfg *config.Config) {
+	apiKeys.ttl = cfg.APIKeys.CacheTTL
+	clientCerts.ttl = cfg.APIKeys.CacheTTL
+}
+
+// get returns the cached entry of keyHash, querying the database on a miss.
+func (c *apiKeyCache) get(keyHash string) (apiKeyEntry, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[keyHash]
+	c.mu.RUnlock()
+	if ok && time.Since(entry.loadedAt) < c.ttl {
+		return entry, nil
+	}
+
+	access, err := c.lookup(keyHash)
+	if err != nil && !errors.Is(err, c.notFound) {
+		return apiKeyEntry{}, err
+	}
+	entry = apiKeyEntry{loadedAt: time.Now()}
+	if access != nil {
+		entry.access = access
+		entry.networks = parseAllowlist(access)
+	}
+
+	c.mu.Lock()
+	if len(c.entries) >= apiKeyCacheMaxEntries {
+		for k, e := range c.entries {
+			if time.Since(e.loadedAt) >= c.ttl {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[keyHash] = entry
+	c.mu.Unlock()
+	return entry, nil
+}
+
+// InvalidateAPIKeys drops every cached key and client certificate so that
+// issued, rotated and revoked keys take effect immediately on this instance.
+func InvalidateAPIKeys() {
+	for _, c := range []*apiKeyCache{apiKeys, clientCerts} {
+		c.mu.Lock()
+		c.entries = make(map[string]apiKeyEntry)
+		c.mu.Unlock()
+	}
+}
+
+// parseAllowlist parses the vendor's allowlist. A plain address is a
+// single-address range and IPv4-mapped entries are stored as IPv4, to match
+// ipAllowed; invalid entries are logged and skipped.
+func parseAllowlist(a *databasecommon.APIKeyAccess) []netip.Prefix {
+	var out []netip.Prefix
+	for _, entry := range a.CIDRs {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			p, err := netip.ParsePrefix(entry)
+			if err != nil {
+				log.Printf("Ignoring invalid allowlist entry %q of vendor %d: %v", entry, a.VendorId, err)
+				continue
+			}
+			if p.Addr().Is4In6() {
+				if p.Bits() < 96 {
+					log.Printf("Ignoring allowlist entry %q of vendor %d: mapped prefix shorter than /96", entry, a.VendorId)
+					continue
+				}
+				p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+			}
+			out = append(out, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			log.Printf("Ignoring invalid allowlist entry %q of vendor %d: %v", entry, a.VendorId, err)
+			continue
+		}
+		out = append(out, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return out
+}
+
+// ipAllowed reports whether ip is inside one of networks.
+func ipAllowed(networks []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range networks {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// HashAPIKey returns the hex SHA-256 of key, the form keys are stored in.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewAPIKey generates a random key. It returns the key, shown to the caller
+// once, its hash and its prefix. Keys are hex so that they pass
+// IsValidIDFromRequest.
+func NewAPIKey() (key, keyHash, prefix string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	key = hex.EncodeToString(buf)
+	return key, HashAPIKey(key), key[:8], nil
+}
+
+// checkAPIKey validates a request to apiName from clientIP with key and
+// returns the status message and the vendor, if the key is known.
+func checkAPIKey(apiName, clientIP, key string) (string, *databasecommon.APIKeyAccess, error) {
+	if key == "" {
+		return APIStatusInvalidKey, nil, nil
+	}
+
+	entry, err := apiKeys.get(HashAPIKey(key))
+	if err != nil {
+		return "", nil, err
+	}
+	status, a := checkAccess(apiName, clientIP, entry)
+	return status, a, nil
+}
+
+// checkAccess validates a request to apiName from clientIP against a cached
+// key or certificate lookup and returns the status message and the vendor,
+// if the key or certificate is known.
+func checkAccess(apiName, clientIP string, entry apiKeyEntry) (string, *databasecommon.APIKeyAccess) {
+	a := entry.access
+	if a == nil || !a.KeyActive {
+		return APIStatusInvalidKey, nil
+	}
+
+	now := time.Now()
+	if !a.KeyExpires.IsZero() && !now.Before(a.KeyExpires) {
+		return APIStatusExpiredKey, a
+	}
+	if !a.Vendor.OpenAt(now) {
+		return APIStatusInactiveVendor, a
+	}
+	grant, ok := a.APIs[apiName]
+	if !ok {
+		return APIStatusInvalidAPIName, a
+	}
+	if !grant.API.OpenAt(now) || !grant.Grant.OpenAt(now) {
+		return APIStatusInactiveAPIName, a
+	}
+	if !ipAllowed(entry.networks, clientIP) {
+		return APIStatusInvalidIP, a
+	}
+	return APIStatusSuccess, a
+}
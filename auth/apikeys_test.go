@@ -0,0 +1,73 @@
+package auth
+
+import (
+	databasecommon "Hrmodule/database/common"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestParseAllowlist(t *testing.T) {
+	tests := []struct {
+		name  string
+		cidrs []string
+		want  []string
+	}{
+		{"IPv4 range", []string{"10.1.0.0/16"}, []string{"10.1.0.0/16"}},
+		{"host bits are masked", []string{"10.1.2.3/16"}, []string{"10.1.0.0/16"}},
+		{"IPv6 range", []string{"2001:db8::/32"}, []string{"2001:db8::/32"}},
+		{"bare IPv4", []string{"192.0.2.7"}, []string{"192.0.2.7/32"}},
+		{"bare IPv6", []string{"2001:db8::1"}, []string{"2001:db8::1/128"}},
+		{"bare IPv4-mapped", []string{"::ffff:192.0.2.7"}, []string{"192.0.2.7/32"}},
+		{"IPv4-mapped range", []string{"::ffff:10.0.0.0/104"}, []string{"10.0.0.0/8"}},
+		{"IPv4-mapped range too wide", []string{"::ffff:0.0.0.0/95"}, nil},
+		{"surrounding spaces", []string{" 10.0.0.0/8 "}, []string{"10.0.0.0/8"}},
+		{"bad CIDR skipped", []string{"10.0.0.0/33", "192.0.2.7"}, []string{"192.0.2.7/32"}},
+		{"bad address skipped", []string{"not-an-ip", "10.0.0.0/8"}, []string{"10.0.0.0/8"}},
+		{"empty entry skipped", []string{""}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseAllowlist(&databasecommon.APIKeyAccess{VendorId: 1, CIDRs: tt.cidrs})
+			var gotStr []string
+			for _, p := range got {
+				gotStr = append(gotStr, p.String())
+			}
+			if !reflect.DeepEqual(gotStr, tt.want) {
+				t.Errorf("parseAllowlist(%q) = %q, want %q", tt.cidrs, gotStr, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPAllowed(t *testing.T) {
+	networks := []netip.Prefix{
+		netip.MustParsePrefix("10.1.0.0/16"),
+		netip.MustParsePrefix("192.0.2.7/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.1.255.1", true},
+		{"10.2.0.1", false},
+		{"192.0.2.7", true},
+		{"192.0.2.8", false},
+		{"::ffff:10.1.0.5", true},
+		{"::ffff:10.2.0.5", false},
+		{"2001:db8:1::5", true},
+		{"2001:db9::5", false},
+		{"::1", false},
+		{"10.1.0.1:443", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ipAllowed(networks, tt.ip); got != tt.want {
+			t.Errorf("ipAllowed(%q) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+	if ipAllowed(nil, "10.1.0.1") {
+		t.Error("ipAllowed with no networks = true, want false")
+	}
+}
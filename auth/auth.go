@@ -1,5 +1,5 @@
 // Package auth provides authentication and authorization functionality,
-// including client IP validation, token validation, and stored procedure integration.
+// including client IP validation and API key (token) validation.
 //
 // --- Creator's Info ---
 //
@@ -9,23 +9,22 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 09-07-2025
+// Last Modified Date: 17-10-2026
 package auth
 
 import (
-	credentials "Hrmodule/dbconfig"
+	databasecommon "Hrmodule/database/common"
 	"Hrmodule/utils"
-	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
 	"unicode"
-
-	_ "github.com/go-sql-driver/mysql" // MySQL driver
 )
 
 // IsValid_IDFromRequest checks if the "token" query parameter is valid (alphanumeric only).
@@ -100,77 +99,75 @@ func IsValidIDFromRequest(r *http.Request) error {
 //   - A wrapped handler that logs the client IP before executing.
 func LogRequestInfo(handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		clientIP := r.RemoteAddr
+		clientIP := ClientIP(r)
 		fmt.Printf("Client IP Address: %s\n", clientIP)
 		handler(w, r)
 	}
 }
 
-// ValidateAPI calls the stored procedure `API_Validation` to determine
-// if the API access is valid, and logs the request and its result to a database.
+// ValidateAPI validates a request to APIName made from clientIPAddress with
+// the API key IDKey (see apikeys.go), and logs the request and its result
+// to Client_Request.
 //
 // Parameters:
 //   - APIName: The name of the API being accessed.
 //   - clientIPAddress: The IP address of the requester.
-//   - IDKey: The token or identifier used to validate the request.
+//   - IDKey: The API key sent with the request.
 //   - requestURL: The full URL of the incoming request.
 //
 // Returns:
 //   - A boolean indicating if the request is valid.
-//   - A status message returned from the stored procedure.
-//   - An error if something goes wrong during validation or logging.
+//   - The status message, one of the APIStatus constants.
+//   - An error if the key could not be looked up.
 func ValidateAPI(APIName, clientIPAddress, IDKey, requestURL string) (bool, string, error) {
-
-	// Step 6: Database connection and operation
-	// Connection string for SQL Server
-	connectionString := credentials.GetMySQLDatabase17()
-
-	db, err := sql.Open("mysql", connectionString)
-	if err != nil {
-		return false, "", fmt.Errorf("DB connection error: %v", err)
-	}
-	defer db.Close()
-
-	stmt, err := db.Prepare("CALL API_Validation_New(?, ?, ?, @statusMessage)")
-	if err != nil {
-		return false, "", err
-	}
-	defer stmt.Close()
-
-	_, err = stmt.Exec(APIName, clientIPAddress, IDKey)
+	statusMessage, access, err := checkAPIKey(APIName, clientIPAddress, IDKey)
 	if err != nil {
 		return false, "", err
 	}
 
-	var statusMessage string
-	err = db.QueryRow("SELECT @StatusMessage").Scan(&statusMessage)
-	if err != nil {
-		return false, "", err
-	}
+	logClientRequest(clientIPAddress, requestURL, statusMessage, access)
+	return statusMessage == APIStatusSuccess, statusMessage, nil
+}
 
+// logClientRequest logs a validated request and its result to
+// Client_Request; a failure to log does not refuse the request.
+func logClientRequest(clientIPAddress, requestURL, statusMessage string, access *databasecommon.APIKeyAccess) {
 	status := ""
 	errorMessage := ""
-	if statusMessage == "Success" {
+	if statusMessage == APIStatusSuccess {
 		status = statusMessage
 	} else {
 		errorMessage = statusMessage
+		if access != nil {
+			errorMessage += " (vendor " + access.VendorName + ", key " + access.KeyPrefix + ")"
+		}
 	}
 
-	// Log the request and insert into Client_Request table
-	_, err = db.Exec(`
-        INSERT INTO Client_Request (
-            Ip_Address, Request_Data, Response_Data,
-            Status, Error, Request_On, Response_On, Updated_On
-        )
-        VALUES (?, ?, '', ?, ?, NOW(), NOW(), NOW())`,
-		clientIPAddress, requestURL, status, errorMessage,
-	)
+	if err := databasecommon.LogClientRequest(clientIPAddress, requestURL, status, errorMessage); err != nil {
+		log.Printf("Client request log failed: %v", err)
+	}
+}
 
+// apiStatusCodes maps validation failures to their HTTP status: 401 when
+// the key itself is not accepted, 403 when the key is valid but not for
+// this API, vendor period or address.
+var apiStatusCodes = map[string]int{
+	APIStatusInvalidKey:      http.StatusUnauthorized,
+	APIStatusExpiredKey:      http.StatusUnauthorized,
+	APIStatusInvalidAPIName:  http.StatusForbidden,
+	APIStatusInactiveAPIName: http.StatusForbidden,
+	APIStatusInactiveVendor:  http.StatusForbidden,
+	APIStatusInvalidIP:       http.StatusForbidden,
+}
+
+// ClientIP returns the address of the caller without the port. Behind a
+// trusted proxy it is taken from X-Forwarded-For (see proxy.go).
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
-		return false, "", err
+		host = r.RemoteAddr
 	}
-
-	return statusMessage == "Success", statusMessage, nil
+	return forwardedClient(r, host)
 }
 
 // Responseset represents the standard API error response format.
@@ -267,7 +264,7 @@ func HandleRequestfor_apiname_ipaddress_token(w http.ResponseWriter, r *http.Req
 	}
 
 	// Extract the clientIPAddress from the request
-	clientIPAddress := strings.Split(r.RemoteAddr, ":")[0]
+	clientIPAddress := ClientIP(r)
 
 	// Extract the token from the header, body or query string
 	var IDKey string
@@ -296,36 +293,30 @@ func HandleRequestfor_apiname_ipaddress_token(w http.ResponseWriter, r *http.Req
 	// Get the entire request URL as a string
 	requestURL := r.URL.String()
 
-	// Validate the API using the token, client IP, and APIName
-	isValid, statusMessage, err := ValidateAPI(APIName, clientIPAddress, IDKey, requestURL)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusForbidden)
-		return false
+	// Validate the API using the token, client IP, and APIName. On the
+	// mutual-TLS listener the verified client certificate replaces the token.
+	var isValid bool
+	var statusMessage string
+	if cert := VerifiedClientCert(r); cert != nil {
+		isValid, statusMessage, err = ValidateClientCert(APIName, clientIPAddress, cert, requestURL)
+	} else {
+		isValid, statusMessage, err = ValidateAPI(APIName, clientIPAddress, IDKey, requestURL)
 	}
-
-	// Handle various API status messages
-	switch statusMessage {
-	case "Invalid_Key":
-		return respondWithError(w, 400, "Invalid_Key")
-	case "Invalid_APIName":
-		return respondWithError(w, 401, "Invalid_APIName")
-	case "Invalid_IPAddress":
-		return respondWithError(w, 402, "Invalid_IPAddress")
-	case "Inactive_APIName":
-		return respondWithError(w, 403, "Inactive_APIName")
-	case "Inactive_Vendor":
-		return respondWithError(w, 404, "Inactive_Vendor")
-	case "Inactive_Ip_Address":
-		return respondWithError(w, 405, "Inactive_Ip_Address")
-	case "UnauthorizedUser":
-		return respondWithError(w, 406, "UnauthorizedUser")
-	case "Invalid_RollNo":
-		return respondWithError(w, 407, "Invalid_RollNo")
+	if err != nil {
+		log.Printf("API key validation failed: %v", err)
+		return respondWithError(w, http.StatusServiceUnavailable, "Unable to validate API key")
 	}
 
-	// If validation fails, return a forbidden error
+	// If validation fails, return 401 or 403 with the status message
 	if !isValid {
-		return respondWithError(w, http.StatusForbidden, statusMessage)
+		code, ok := apiStatusCodes[statusMessage]
+		if !ok {
+			code = http.StatusForbidden
+		}
+		if code == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", `ApiKey realm="Hrmodule"`)
+		}
+		return respondWithError(w, code, statusMessage)
 	}
 
 	return true
@@ -349,17 +340,24 @@ func respondWithError(w http.ResponseWriter, statusCode int, message string) boo
 		Data:    []string{},
 	}
 
+	writeEncrypted(w, statusCode, response)
+	return false
+}
+
+// writeEncrypted marshals response, encrypts it using AES-GCM and sends it
+// as a JSON object with a "Data" key and the given status code.
+func writeEncrypted(w http.ResponseWriter, statusCode int, response interface{}) {
 	responseJSON, err := json.MarshalIndent(response, "", "    ")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return false
+		return
 	}
 
 	// Encrypt the error response
 	encrypted, err := utils.Encrypt(responseJSON)
 	if err != nil {
 		http.Error(w, "Encryption failed", http.StatusInternalServerError)
-		return false
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -367,6 +365,4 @@ func respondWithError(w http.ResponseWriter, statusCode int, message string) boo
 	_ = json.NewEncoder(w).Encode(map[string]string{
 		"Data": encrypted,
 	})
-
-	return false
 }
@@ -0,0 +1,145 @@
+// Package auth provides authentication and authorization functionality,
+// including the RequireRole and RequirePermission middlewares that check
+// the caller's active roles against the route policy table.
+//
+// Permissions are named capabilities used by routes and handlers. The roles
+// granting each permission are configured in ROLE_PERMISSIONS as
+// "permission=Role A|Role B;permission=Role C" because role names are data
+// in ROLEMASTER, not code.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package auth
+
+import (
+	"Hrmodule/config"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Permission names checked by routes and handlers.
+const (
+	PermWorkflowAct    = "workflow.act"     // act on workflow records (/Inboxactivity)
+	PermInboxReadAny   = "inbox.read.any"   // read another employee's inbox
+	PermRolesReadAny   = "roles.read.any"   // read another user's roles
+	PermSessionReadAny = "session.read.any" // read or close another user's session
+	PermLoginUnlock    = "login.unlock"     // lift a login lockout (/LoginUnlock)
+	PermImpersonate    = "impersonate"      // act as another employee (/Impersonate)
+	PermAPIKeyManage   = "apikey.manage"    // issue, rotate and revoke API keys
+	PermHealthView     = "health.view"      // read the dependency report (/HealthReport)
+)
+
+// permissionRoles maps a permission to the role names that grant it.
+var permissionRoles = map[string][]string{}
+
+// configurePermissions applies ROLE_PERMISSIONS.
+func configurePermissions(cfg *config.Config) error {
+	if cfg.Roles.Permissions == "" {
+		return nil
+	}
+	parsed, err := parsePermissionRoles(cfg.Roles.Permissions)
+	if err != nil {
+		return fmt.Errorf("ROLE_PERMISSIONS: %v", err)
+	}
+	permissionRoles = parsed
+	return nil
+}
+
+// parsePermissionRoles parses "perm=Role A|Role B;perm=Role C".
+func parsePermissionRoles(v string) (map[string][]string, error) {
+	out := make(map[string][]string)
+	for _, entry := range strings.Split(v, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		perm, names, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(perm) == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected permission=Role|Role", entry)
+		}
+		for _, name := range strings.Split(names, "|") {
+			if name = strings.TrimSpace(name); name != "" {
+				out[strings.TrimSpace(perm)] = append(out[strings.TrimSpace(perm)], name)
+			}
+		}
+	}
+	return out, nil
+}
+
+// HasPermission reports whether the authenticated user holds a role that
+// grants permission. Admin roles hold every permission.
+func HasPermission(ctx context.Context, permission string) (bool, error) {
+	return HasRole(ctx, permissionRoles[permission]...)
+}
+
+// Can is HasPermission for handler checks; lookup errors are logged and
+// treated as "not permitted".
+func Can(ctx context.Context, permission string) bool {
+	ok, err := HasPermission(ctx, permission)
+	if err != nil {
+		log.Printf("Role lookup failed: %v", err)
+		return false
+	}
+	return ok
+}
+
+// RequireRole allows the request only if the caller holds one of the roles.
+// It must run after JwtMiddleware.
+func RequireRole(names ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, err := HasRole(r.Context(), names...)
+			if err != nil {
+				log.Printf("Role lookup failed: %v", err)
+				respondWithError(w, http.StatusServiceUnavailable, "Unable to resolve roles")
+				return
+			}
+			if !ok {
+				respondForbiddenRoles(w, "Forbidden: requires one of the listed roles", names)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermission allows the request only if the caller holds a role
+// granting permission. It must run after JwtMiddleware.
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, err := HasPermission(r.Context(), permission)
+			if err != nil {
+				log.Printf("Role lookup failed: %v", err)
+				respondWithError(w, http.StatusServiceUnavailable, "Unable to resolve roles")
+				return
+			}
+			if !ok {
+				respondForbiddenRoles(w, "Forbidden: requires permission "+permission, permissionRoles[permission])
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// respondForbiddenRoles writes an encrypted 403 whose Data lists the roles
+// that would have been accepted.
+func respondForbiddenRoles(w http.ResponseWriter, message string, accepted []string) {
+	response := Responseset{
+		Status:  http.StatusForbidden,
+		Message: message,
+		Data:    append([]string{}, accepted...),
+	}
+	writeEncrypted(w, http.StatusForbidden, response)
+}
@@ -0,0 +1,141 @@
+// Package auth provides authentication and authorization functionality,
+// including the typed JWT claims that JwtMiddleware stores in the request
+// context for handlers to scope their queries.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims is the payload of the access tokens issued at login.
+type Claims struct {
+	UserId     string `json:"userId"`          // Identifier generated at login
+	Username   string `json:"username"`        // LDAP login name
+	EmployeeId string `json:"employeeId"`      // EmployeeId from employeebasicinfo
+	Session    string `json:"session"`         // Session_Id of the session_data row
+	Scope      string `json:"scope,omitempty"` // "" for session tokens, ScopePreAuth during login
+	Actor      *Actor `json:"act,omitempty"`   // Set on impersonation tokens: the admin acting as the user
+	jwt.RegisteredClaims
+}
+
+// Actor identifies the admin behind an impersonation token. The token's
+// Username and EmployeeId are the impersonated employee's, so handlers see
+// exactly what that employee sees; its Session is the admin's session.
+type Actor struct {
+	Username   string `json:"username"`
+	EmployeeId string `json:"employeeId"`
+}
+
+// ScopePreAuth marks the short-lived token returned by /HRldap. It only
+// admits the second-factor endpoints; Session then holds the login
+// transaction id rather than a session_data row.
+const ScopePreAuth = "preauth"
+
+// contextKey is unexported so no other package can collide with it.
+type contextKey string
+
+const claimsContextKey contextKey = "claims"
+
+// NewClaims builds the claims for an access token valid for ttl.
+func NewClaims(userId, username, employeeId, session string, ttl time.Duration) *Claims {
+	now := time.Now()
+	return &Claims{
+		UserId:     userId,
+		Username:   username,
+		EmployeeId: employeeId,
+		Session:    session,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   username,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+}
+
+// NewPreAuthClaims builds the claims for a pre-auth token of the login
+// transaction txId, valid for ttl.
+func NewPreAuthClaims(username, employeeId, txId string, ttl time.Duration) *Claims {
+	claims := NewClaims(txId, username, employeeId, txId, ttl)
+	claims.Scope = ScopePreAuth
+	return claims
+}
+
+// NewImpersonationClaims builds the claims of a token that lets the admin
+// of actor act as targetUsername for ttl. It is bound to the admin's session,
+// so logging that session out ends the impersonation too.
+func NewImpersonationClaims(actor *Claims, targetUsername, targetEmployeeId string, ttl time.Duration) *Claims {
+	claims := NewClaims(actor.UserId, targetUsername, targetEmployeeId, actor.Session, ttl)
+	claims.Actor = &Actor{Username: actor.Username, EmployeeId: actor.EmployeeId}
+	return claims
+}
+
+// WithClaims returns a copy of ctx carrying the verified claims.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey, claims)
+}
+
+// ClaimsFromContext returns the verified claims stored by JwtMiddleware.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// UsernameFromContext returns the authenticated username, or "" if none.
+func UsernameFromContext(ctx context.Context) string {
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		return claims.Username
+	}
+	return ""
+}
+
+// EmployeeIdFromContext returns the authenticated EmployeeId, or "" if none.
+func EmployeeIdFromContext(ctx context.Context) string {
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		return claims.EmployeeId
+	}
+	return ""
+}
+
+// SessionFromContext returns the authenticated Session_Id, or "" if none.
+func SessionFromContext(ctx context.Context) string {
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		return claims.Session
+	}
+	return ""
+}
+
+// UserIdFromContext returns the authenticated userId, or "" if none.
+func UserIdFromContext(ctx context.Context) string {
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		return claims.UserId
+	}
+	return ""
+}
+
+// ActorFromContext returns the admin behind an impersonation token, or
+// false if the request is made by the user themselves.
+func ActorFromContext(ctx context.Context) (*Actor, bool) {
+	if claims, ok := ClaimsFromContext(ctx); ok && claims.Actor != nil {
+		return claims.Actor, true
+	}
+	return nil, false
+}
+
+// RespondForbidden writes an encrypted 403 response in the standard Responseset format.
+func RespondForbidden(w http.ResponseWriter, message string) bool {
+	return respondWithError(w, http.StatusForbidden, message)
+}
@@ -0,0 +1,68 @@
+// Package auth validates requests made on the mutual-TLS listener, where
+// internal campus services authenticate with a client certificate instead
+// of an API key.
+//
+// The TLS handshake has already verified the certificate against
+// MTLS_CLIENT_CA_FILE. The certificate's SHA-256 fingerprint must also be
+// registered to a vendor in api_vendor_cert; the request is then checked
+// exactly like an API key of that vendor: vendor period, API grants and IP
+// allowlist. Lookups share the API key cache TTL and InvalidateAPIKeys.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package auth
+
+import (
+	databasecommon "Hrmodule/database/common"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+var clientCerts = &apiKeyCache{
+	ttl:      time.Minute,
+	entries:  make(map[string]apiKeyEntry),
+	lookup:   databasecommon.ClientCertByFingerprint,
+	notFound: databasecommon.ErrClientCertNotFound,
+}
+
+// VerifiedClientCert returns the client certificate verified during the
+// TLS handshake, or nil when the request did not come over mutual TLS.
+func VerifiedClientCert(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
+
+// CertFingerprint returns the hex SHA-256 of the DER certificate, the form
+// certificates are registered in.
+func CertFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateClientCert validates a request to APIName made from
+// clientIPAddress with the verified client certificate cert, and logs the
+// request and its result to Client_Request. It returns the same status
+// messages as ValidateAPI; Invalid_Key means the certificate is not
+// registered or not active.
+func ValidateClientCert(APIName, clientIPAddress string, cert *x509.Certificate, requestURL string) (bool, string, error) {
+	entry, err := clientCerts.get(CertFingerprint(cert))
+	if err != nil {
+		return false, "", err
+	}
+	statusMessage, access := checkAccess(APIName, clientIPAddress, entry)
+
+	logClientRequest(clientIPAddress, requestURL, statusMessage, access)
+	return statusMessage == APIStatusSuccess, statusMessage, nil
+}
@@ -0,0 +1,40 @@
+// Package auth applies the loaded configuration to its caches, key ring and
+// permission table.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package auth
+
+import "Hrmodule/config"
+
+// Configure applies cfg to the package. It is called once from main before
+// the server starts; every problem found is returned together.
+func Configure(cfg *config.Config) error {
+	configureRoles(cfg)
+	configureImpersonation(cfg)
+	configureActivity(cfg)
+	configureSessionCache(cfg)
+	configureAPIKeys(cfg)
+
+	var errs config.Errors
+	if err := configurePermissions(cfg); err != nil {
+		errs = append(errs, err)
+	}
+	if err := configureProxies(cfg); err != nil {
+		errs = append(errs, err)
+	}
+	if err := configureKeys(cfg); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package auth provides authentication and authorization functionality,
+// including the guard applied to impersonation ("act as employee") tokens.
+//
+// It ensures:
+//   - An impersonation token only reaches the routes listed in
+//     IMPERSONATION_ALLOWED_ROUTES; every other route, including all
+//     mutating ones such as /Inboxactivity, answers 403
+//   - Every request made with such a token, allowed or not, is written to
+//     impersonation_audit before it is served, with its status afterwards
+//   - If the audit row cannot be written the request is refused
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package auth
+
+import (
+	"Hrmodule/config"
+	databaselogin "Hrmodule/database/login"
+	"log"
+	"net/http"
+	"sort"
+)
+
+// impersonationRoutes lists the routes an impersonation token may call.
+// The default only holds read-only routes; adding a mutating route to
+// IMPERSONATION_ALLOWED_ROUTES is the explicit opt-in.
+var impersonationRoutes = map[string]bool{
+	"/TaskInbox":    true,
+	"/Defaultrole":  true,
+	"/Statusmaster": true,
+}
+
+// configureImpersonation applies IMPERSONATION_ALLOWED_ROUTES.
+func configureImpersonation(cfg *config.Config) {
+	if len(cfg.Impersonation.AllowedRoutes) > 0 {
+		impersonationRoutes = map[string]bool{}
+		for _, route := range cfg.Impersonation.AllowedRoutes {
+			impersonationRoutes[route] = true
+		}
+	}
+}
+
+// ImpersonationRoutes returns the routes impersonation tokens may call, sorted.
+func ImpersonationRoutes() []string {
+	routes := make([]string, 0, len(impersonationRoutes))
+	for route := range impersonationRoutes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// statusRecorder remembers the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// serveImpersonated audits a request carrying an impersonation token and
+// serves it only if its route allows impersonation. r already carries claims.
+func serveImpersonated(w http.ResponseWriter, r *http.Request, claims *Claims, next http.Handler) {
+	allowed := impersonationRoutes[r.URL.Path]
+
+	auditId, err := databaselogin.InsertImpersonationAudit(databaselogin.ImpersonationAudit{
+		ActorUsername:    claims.Actor.Username,
+		ActorEmployeeId:  claims.Actor.EmployeeId,
+		SessionId:        claims.Session,
+		TargetUsername:   claims.Username,
+		TargetEmployeeId: claims.EmployeeId,
+		Method:           r.Method,
+		Route:            r.URL.Path,
+		Allowed:          allowed,
+		ClientIP:         ClientIP(r),
+	})
+	if err != nil {
+		log.Printf("Impersonation audit failed for %s as %s: %v", claims.Actor.Username, claims.Username, err)
+		respondWithError(w, http.StatusServiceUnavailable, "Unable to audit impersonated request")
+		return
+	}
+
+	rec := &statusRecorder{ResponseWriter: w}
+	if allowed {
+		next.ServeHTTP(rec, r)
+	} else {
+		respondWithError(rec, http.StatusForbidden, "Forbidden: route not allowed while impersonating")
+	}
+
+	if err := databaselogin.CompleteImpersonationAudit(auditId, rec.status); err != nil {
+		log.Printf("Impersonation audit %d not completed: %v", auditId, err)
+	}
+}
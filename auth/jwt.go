@@ -10,65 +10,129 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 25-08-2025
+// Last Modified Date: 17-10-2026
 package auth
 
 import (
+	"Hrmodule/config"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
+)
 
-	"os"
+// Keys holds the key ring used for signing and verifying JWT tokens.
+// It is loaded from the PEM files listed in `JWT_KEYS` ("kid=path,...");
+// `JWT_SIGNING_KEY_ID` selects the active signing key.
+var Keys *KeyRing
 
-	"github.com/golang-jwt/jwt/v5"
-)
+// configureKeys loads the key ring from JWT_KEYS and JWT_SIGNING_KEY_ID.
+func configureKeys(cfg *config.Config) error {
+	files, err := parseKeyFiles(cfg.JWT.Keys)
+	if err != nil {
+		return fmt.Errorf("JWT_KEYS: %v", err)
+	}
+
+	Keys, err = LoadKeyRing(cfg.JWT.SigningKeyID, files)
+	if err != nil {
+		return fmt.Errorf("JWT_KEYS: failed to load keys: %v", err)
+	}
+	return nil
+}
 
-// JwtKey holds the secret key used for signing and verifying JWT tokens.
-// It is initialized from the environment variable `JWT_SECRET_KEY`.
-var JwtKey []byte
+// SignClaims signs claims with the active key of the key ring.
+func SignClaims(claims *Claims) (string, error) {
+	return Keys.Sign(claims)
+}
 
-// init initializes the JwtKey by reading the JWT_SECRET_KEY environment variable.
-// If the environment variable is not set, the application will panic.
-func init() {
-	key := os.Getenv("JWT_SECRET_KEY")
-	if key == "" {
-		panic("JWT_SECRET_KEY environment variable not set")
+// bearerClaims parses and verifies the Bearer token of r. On failure it
+// returns the message to send with a 401.
+func bearerClaims(r *http.Request) (*Claims, string) {
+	// Get token from Authorization header
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, "Authorization header missing"
 	}
-	JwtKey = []byte(key)
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		return nil, "Invalid Authorization header format"
+	}
+
+	// Parse and validate the token
+	claims := &Claims{}
+	token, err := Keys.Parse(tokenString, claims)
+
+	if err != nil || !token.Valid {
+		return nil, "Invalid or expired token"
+	}
+
+	if claims.Username == "" || claims.Session == "" {
+		return nil, "Invalid or expired token"
+	}
+	return claims, ""
 }
 
-// JwtMiddleware checks for JWT token and validates it
+// JwtMiddleware checks for JWT token, validates it, rejects it if its session
+// is no longer active and stores the verified Claims in the request context
+// (see ClaimsFromContext). Pre-auth tokens are refused, and impersonation
+// tokens only reach the routes in IMPERSONATION_ALLOWED_ROUTES.
 func JwtMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, msg := bearerClaims(r)
+		if claims == nil {
+			http.Error(w, msg, http.StatusUnauthorized)
+			return
+		}
+
+		// A pre-auth token has not passed the second factor yet
+		if claims.Scope != "" {
+			http.Error(w, "Login not completed", http.StatusUnauthorized)
+			return
+		}
+
+		// The token is only as good as the session it was issued for
+		active, err := SessionActive(claims.Session)
+		if err != nil {
+			log.Printf("Session lookup failed for %s: %v", claims.Session, err)
+			http.Error(w, "Unable to verify session", http.StatusServiceUnavailable)
+			return
+		}
+		if !active {
+			http.Error(w, "Session is no longer active", http.StatusUnauthorized)
+			return
+		}
 
-		// Get token from Authorization header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Authorization header missing", http.StatusUnauthorized)
+		// Restart the idle window; written to session_data in batches
+		TouchSession(claims.Session)
+
+		// Impersonation tokens are limited to allowed routes and audited
+		if claims.Actor != nil {
+			serveImpersonated(w, r.WithContext(WithClaims(r.Context(), claims)), claims, next)
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			http.Error(w, "Invalid Authorization header format", http.StatusUnauthorized)
+		// Token is valid -> call next handler with the claims in context
+		next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
+	})
+}
+
+// PreAuthMiddleware admits only the pre-auth token issued by /HRldap and
+// stores its Claims in the request context. The handler must still check
+// the state of the login transaction (Claims.Session).
+func PreAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, msg := bearerClaims(r)
+		if claims == nil {
+			http.Error(w, msg, http.StatusUnauthorized)
 			return
 		}
 
-		// Parse and validate the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Ensure token method is HMAC
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return JwtKey, nil
-		})
-
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		if claims.Scope != ScopePreAuth {
+			http.Error(w, "Pre-auth token required", http.StatusUnauthorized)
 			return
 		}
 
-		// Token is valid -> call next handler
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
 	})
 }
@@ -0,0 +1,221 @@
+// Package auth provides authentication and authorization functionality,
+// including the asymmetric key ring used to sign and verify JWTs and the
+// JWKS endpoint that publishes the verification keys.
+//
+// Keys are loaded from PEM files. Exactly one key is active and is used for
+// signing; every other configured key is retired and still accepted for
+// verification until it is removed from the configuration.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is one entry of the key ring.
+type signingKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer    // nil for keys loaded from a public PEM
+	Public  crypto.PublicKey // *rsa.PublicKey or ed25519.PublicKey
+}
+
+// KeyRing holds the active signing key and the retired verification keys.
+type KeyRing struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+// LoadKeyRing loads every key in files (kid -> PEM path) and makes activeKid
+// the signing key. The active key file must contain a private key; retired
+// keys may be given as public keys only.
+func LoadKeyRing(activeKid string, files map[string]string) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string]*signingKey)}
+
+	for kid, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %v", kid, err)
+		}
+		key, err := parsePEMKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %v", kid, err)
+		}
+		ring.keys[kid] = key
+	}
+
+	active, ok := ring.keys[activeKid]
+	if !ok {
+		return nil, fmt.Errorf("active key %q is not in the key list", activeKid)
+	}
+	if active.Private == nil {
+		return nil, fmt.Errorf("active key %q has no private key", activeKid)
+	}
+	ring.active = active
+
+	return ring, nil
+}
+
+// parsePEMKey decodes an RSA or Ed25519 key from PEM data.
+func parsePEMKey(kid string, data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{ID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	if rsaKey, ok := key.Public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must be at least 2048 bits")
+	}
+	return key, nil
+}
+
+// parseKeyFiles parses "kid=path,kid=path" into a map.
+func parseKeyFiles(v string) (map[string]string, error) {
+	files := make(map[string]string)
+	for _, entry := range splitList(v) {
+		kid, path, ok := strings.Cut(entry, "=")
+		kid, path = strings.TrimSpace(kid), strings.TrimSpace(path)
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid key entry %q, expected kid=path", entry)
+		}
+		files[kid] = path
+	}
+	return files, nil
+}
+
+// Sign signs claims with the active key and sets the kid header.
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.Method, claims)
+	token.Header["kid"] = k.active.ID
+	return token.SignedString(k.active.Private)
+}
+
+// keyFunc resolves the verification key from the token's kid header and
+// rejects tokens whose algorithm does not match that key.
+func (k *KeyRing) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.Public, nil
+}
+
+// Parse verifies tokenString and decodes it into claims.
+func (k *KeyRing) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, k.keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+}
+
+// JWK is a single JSON Web Key as published on the JWKS endpoint.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS returns the public keys of the ring, ordered by kid.
+func (k *KeyRing) JWKS() []JWK {
+	kids := make([]string, 0, len(k.keys))
+	for kid := range k.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := make([]JWK, 0, len(kids))
+	for _, kid := range kids {
+		key := k.keys[kid]
+		entry := JWK{Kid: kid, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			entry.Kty = "RSA"
+			entry.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			entry.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			entry.Kty = "OKP"
+			entry.Crv = "Ed25519"
+			entry.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		keys = append(keys, entry)
+	}
+	return keys
+}
+
+// JWKSHandler serves the verification keys at /.well-known/jwks.json so
+// other services can validate our tokens without a shared secret.
+func JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed, use GET", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": Keys.JWKS(),
+	})
+}
@@ -0,0 +1,99 @@
+// Package auth resolves the client address of requests that arrive through
+// a trusted reverse proxy.
+//
+// When the server runs behind a TLS-terminating proxy (SERVER_BEHIND_PROXY),
+// RemoteAddr is the proxy. The proxy appends the address it received the
+// request from to X-Forwarded-For, so the client is found by walking that
+// header from the right and skipping every hop listed in TRUSTED_PROXIES.
+// The header is ignored when the request does not come from a trusted proxy,
+// so a client cannot choose its own address.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package auth
+
+import (
+	"Hrmodule/config"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// trustedProxies lists the proxies whose X-Forwarded-For is believed.
+var trustedProxies []netip.Prefix
+
+// configureProxies applies TRUSTED_PROXIES.
+func configureProxies(cfg *config.Config) error {
+	var prefixes []netip.Prefix
+	for _, entry := range cfg.Server.TrustedProxies {
+		prefix, err := parsePrefix(entry)
+		if err != nil {
+			return fmt.Errorf("TRUSTED_PROXIES: %q is not an IP address or CIDR", entry)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	trustedProxies = prefixes
+	return nil
+}
+
+// parsePrefix parses a CIDR or a single address.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// isTrustedProxy reports whether host is one of the trusted proxies.
+func isTrustedProxy(host string) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedClient returns the client address for a request received from
+// peer: the right-most X-Forwarded-For hop that is not a trusted proxy, or
+// peer itself when peer is not trusted or the header has no usable hop.
+func forwardedClient(r *http.Request, peer string) string {
+	if !isTrustedProxy(peer) {
+		return peer
+	}
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			break
+		}
+		client = hop
+		if !isTrustedProxy(hop) {
+			break
+		}
+	}
+	return client
+}
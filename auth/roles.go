@@ -0,0 +1,159 @@
+// Package auth provides authentication and authorization functionality,
+// including resolution of the roles held by the authenticated user.
+//
+// Active roles come from USERMASTER → ORGUNITUSERMAPPING → ORGUNITROLEMAPPING
+// → ROLEMASTER and are cached per username for ROLE_CACHE_TTL.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package auth
+
+import (
+	"Hrmodule/config"
+	databasecommon "Hrmodule/database/common"
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// adminRoleNames lists the ROLEMASTER role names allowed to act on other
+// employees' data. It is read from ADMIN_ROLE_NAMES (comma separated).
+// Admin roles implicitly hold every permission.
+var adminRoleNames = []string{"Admin"}
+
+// configureRoles applies ADMIN_ROLE_NAMES and ROLE_CACHE_TTL.
+func configureRoles(cfg *config.Config) {
+	if len(cfg.Roles.AdminRoleNames) > 0 {
+		adminRoleNames = cfg.Roles.AdminRoleNames
+	}
+	roles.ttl = cfg.Roles.CacheTTL
+}
+
+// splitList splits a comma separated value and drops empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// roleEntry is the cached role list of one user.
+type roleEntry struct {
+	roles    []string
+	loadedAt time.Time
+}
+
+// roleCache caches the active role names per username.
+type roleCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]roleEntry
+	lookup  func(username string) ([]string, error)
+}
+
+var roles = &roleCache{
+	ttl:     5 * time.Minute,
+	entries: make(map[string]roleEntry),
+	lookup:  databasecommon.ActiveRoleNames,
+}
+
+// get returns the cached roles of username, querying the database on a miss.
+func (c *roleCache) get(username string) ([]string, error) {
+	key := strings.ToLower(username)
+
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok && time.Since(entry.loadedAt) < c.ttl {
+		return entry.roles, nil
+	}
+
+	names, err := c.lookup(username)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = roleEntry{roles: names, loadedAt: time.Now()}
+	c.mu.Unlock()
+	return names, nil
+}
+
+// InvalidateRoles drops the cached roles of the given users so the next
+// request resolves them again.
+func InvalidateRoles(usernames ...string) {
+	roles.mu.Lock()
+	defer roles.mu.Unlock()
+	for _, username := range usernames {
+		delete(roles.entries, strings.ToLower(username))
+	}
+}
+
+// RolesOf returns the active role names of username, through the role cache.
+// It is used before a session exists, e.g. to apply per-role login policies.
+func RolesOf(username string) ([]string, error) {
+	return roles.get(username)
+}
+
+// RolesFromContext returns the active role names of the authenticated user.
+func RolesFromContext(ctx context.Context) ([]string, error) {
+	username := UsernameFromContext(ctx)
+	if username == "" {
+		return nil, nil
+	}
+	return roles.get(username)
+}
+
+// containsRole reports whether held contains any of wanted, ignoring case.
+func containsRole(held, wanted []string) bool {
+	for _, role := range held {
+		for _, want := range wanted {
+			if strings.EqualFold(role, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the authenticated user holds any of the given roles
+// or an admin role.
+func HasRole(ctx context.Context, names ...string) (bool, error) {
+	held, err := RolesFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	return containsRole(held, adminRoleNames) || containsRole(held, names), nil
+}
+
+// UserIsAdmin reports whether username holds one of the admin roles.
+func UserIsAdmin(username string) (bool, error) {
+	held, err := roles.get(username)
+	if err != nil {
+		return false, err
+	}
+	return containsRole(held, adminRoleNames), nil
+}
+
+// IsAdmin reports whether the authenticated user holds one of the admin roles.
+// Lookup errors are logged and treated as "not admin".
+func IsAdmin(ctx context.Context) bool {
+	held, err := RolesFromContext(ctx)
+	if err != nil {
+		log.Printf("Role lookup failed: %v", err)
+		return false
+	}
+	return containsRole(held, adminRoleNames)
+}
@@ -0,0 +1,99 @@
+// Package auth provides authentication and authorization functionality,
+// including the session cache that lets JwtMiddleware reject tokens whose
+// session_data row has been logged out, timed out or force-killed.
+//
+// Lookups are cached in-process for SESSION_CACHE_TTL so that Postgres is
+// not queried on every request. Code that closes a session calls
+// InvalidateSession so the change takes effect immediately on this instance;
+// other instances pick it up once their cache entry expires.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package auth
+
+import (
+	"Hrmodule/config"
+	databaselogin "Hrmodule/database/login"
+	"sync"
+	"time"
+)
+
+// sessionCacheMaxEntries bounds the cache; expired entries are swept when it is reached.
+const sessionCacheMaxEntries = 10000
+
+// sessionEntry is the cached state of one session.
+type sessionEntry struct {
+	active    bool
+	checkedAt time.Time
+}
+
+// sessionCache caches the Is_Active flag of session_data rows.
+type sessionCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]sessionEntry
+	lookup  func(sessionId string) (bool, error)
+}
+
+var sessions = &sessionCache{
+	ttl:     30 * time.Second,
+	entries: make(map[string]sessionEntry),
+	lookup:  databaselogin.SessionIsActive,
+}
+
+// configureSessionCache applies SESSION_CACHE_TTL.
+func configureSessionCache(cfg *config.Config) {
+	sessions.ttl = cfg.Sessions.CacheTTL
+}
+
+// active returns the cached state of sessionId, querying the database on a miss.
+func (c *sessionCache) active(sessionId string) (bool, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[sessionId]
+	c.mu.RUnlock()
+	if ok && time.Since(entry.checkedAt) < c.ttl {
+		return entry.active, nil
+	}
+
+	active, err := c.lookup(sessionId)
+	if err != nil {
+		return false, err
+	}
+	c.set(sessionId, active)
+	return active, nil
+}
+
+// set stores the state of a session, sweeping expired entries when the cache is full.
+func (c *sessionCache) set(sessionId string, active bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= sessionCacheMaxEntries {
+		for id, entry := range c.entries {
+			if time.Since(entry.checkedAt) >= c.ttl {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[sessionId] = sessionEntry{active: active, checkedAt: time.Now()}
+}
+
+// SessionActive reports whether the session a token was issued for is still active.
+func SessionActive(sessionId string) (bool, error) {
+	return sessions.active(sessionId)
+}
+
+// InvalidateSession marks sessions as closed in the cache. It must be called
+// after the session_data rows have been updated.
+func InvalidateSession(sessionIds ...string) {
+	for _, id := range sessionIds {
+		sessions.set(id, false)
+	}
+}
@@ -0,0 +1,363 @@
+// Package config loads the application configuration into one typed Config.
+//
+// Every setting has a name (the env tag) and is read, lowest precedence
+// first, from Defaults, an optional YAML or TOML file, the .env file and
+// the process environment. Load collects every invalid or missing value and
+// reports them together instead of stopping at the first one.
+//
+// Fields tagged secret:"true" are never printed; see Config.Summary.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package config
+
+import "time"
+
+// Config is the complete application configuration.
+type Config struct {
+	Server        Server
+	Security      Security
+	JWT           JWT
+	Tokens        Tokens
+	Roles         Roles
+	Sessions      Sessions
+	Impersonation Impersonation
+	APIKeys       APIKeys
+	LoginThrottle LoginThrottle
+	TOTP          TOTP
+	OTP           OTP
+	Directory     Directory
+	Databases     Databases
+	Health        Health
+}
+
+// Server holds the HTTP listener settings.
+type Server struct {
+	Port        string   `env:"SERVER_PORT" check:"required"`
+	TLSCertFile string   `env:"TLS_CERT_FILE"`
+	TLSKeyFile  string   `env:"TLS_KEY_FILE"`
+	CORSOrigins []string `env:"CORS_ALLOWED_ORIGINS"` // sent credentials, so never "*"; empty allows none
+
+	// TLSReloadInterval is how often the certificate files are checked for
+	// a renewed certificate.
+	TLSReloadInterval time.Duration `env:"TLS_RELOAD_INTERVAL" check:"positive"`
+
+	// MTLSPort, when set, opens a second HTTPS listener that requires a
+	// client certificate signed by MTLSClientCAFile (see auth.ValidateClientCert).
+	MTLSPort         string `env:"MTLS_PORT"`
+	MTLSClientCAFile string `env:"MTLS_CLIENT_CA_FILE"`
+
+	// BehindProxy serves plain HTTP for a TLS-terminating reverse proxy.
+	// X-Forwarded-For is only believed from TrustedProxies (IPs or CIDRs).
+	BehindProxy    bool     `env:"SERVER_BEHIND_PROXY"`
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+
+	ReadTimeout       time.Duration `env:"SERVER_READ_TIMEOUT" check:"positive"`
+	ReadHeaderTimeout time.Duration `env:"SERVER_READ_HEADER_TIMEOUT" check:"positive"`
+	WriteTimeout      time.Duration `env:"SERVER_WRITE_TIMEOUT" check:"positive"`
+	IdleTimeout       time.Duration `env:"SERVER_IDLE_TIMEOUT" check:"positive"`
+	MaxHeaderBytes    int           `env:"SERVER_MAX_HEADER_BYTES" check:"positive"`
+	ShutdownTimeout   time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" check:"positive"`
+}
+
+// Security holds the key used to encrypt API responses and LDAP passwords.
+type Security struct {
+	EncryptionKey string `env:"ENCRYPTION_KEY" secret:"true" check:"required"`
+}
+
+// JWT holds the signing key ring: "kid=path,..." and the active kid.
+type JWT struct {
+	Keys         string `env:"JWT_KEYS" check:"required"`
+	SigningKeyID string `env:"JWT_SIGNING_KEY_ID" check:"required"`
+}
+
+// Tokens holds token lifetimes.
+type Tokens struct {
+	AccessTTL        time.Duration `env:"ACCESS_TOKEN_TTL" check:"positive"`
+	RefreshTTL       time.Duration `env:"REFRESH_TOKEN_TTL" check:"positive"`
+	PreAuthTTL       time.Duration `env:"PREAUTH_TOKEN_TTL" check:"positive"`
+	ImpersonationTTL time.Duration `env:"IMPERSONATION_TOKEN_TTL" check:"positive"`
+}
+
+// Roles holds role resolution and permission settings.
+type Roles struct {
+	AdminRoleNames []string      `env:"ADMIN_ROLE_NAMES"`
+	CacheTTL       time.Duration `env:"ROLE_CACHE_TTL" check:"positive"`
+	Permissions    string        `env:"ROLE_PERMISSIONS"` // permission=Role A|Role B;...
+	Mapping        string        `env:"ROLE_MAPPING"`     // directory attribute to role rules
+}
+
+// Sessions holds session cache, concurrency and idle timeout settings.
+type Sessions struct {
+	CacheTTL          time.Duration `env:"SESSION_CACHE_TTL" check:"positive"`
+	DefaultPolicy     string        `env:"SESSION_DEFAULT_POLICY" check:"required"` // max:evict|refuse
+	RolePolicies      string        `env:"SESSION_ROLE_POLICIES"`                   // Role=max:action;...
+	IdleTimeout       time.Duration `env:"SESSION_IDLE_TIMEOUT" check:"positive"`
+	RoleIdleTimeouts  string        `env:"SESSION_ROLE_IDLE_TIMEOUTS"` // Role=duration;...
+	ReaperInterval    time.Duration `env:"SESSION_REAPER_INTERVAL" check:"positive"`
+	ActivityFlushTime time.Duration `env:"SESSION_ACTIVITY_FLUSH" check:"positive"`
+}
+
+// Impersonation holds the routes an impersonation token may call.
+type Impersonation struct {
+	AllowedRoutes []string `env:"IMPERSONATION_ALLOWED_ROUTES"`
+}
+
+// APIKeys holds API key validation and issuing settings.
+type APIKeys struct {
+	CacheTTL    time.Duration `env:"API_KEY_CACHE_TTL" check:"positive"`
+	DefaultTTL  time.Duration `env:"API_KEY_DEFAULT_TTL" check:"nonnegative"` // 0: keys do not expire
+	RotateGrace time.Duration `env:"API_KEY_ROTATE_GRACE" check:"nonnegative"`
+}
+
+// LoginThrottle holds the /HRldap brute-force protection settings.
+type LoginThrottle struct {
+	BackoffAfter         int           `env:"LOGIN_BACKOFF_AFTER" check:"positive"`
+	BackoffBase          time.Duration `env:"LOGIN_BACKOFF_BASE" check:"positive"`
+	BackoffMax           time.Duration `env:"LOGIN_BACKOFF_MAX" check:"positive"`
+	UserLockoutThreshold int           `env:"LOGIN_USER_LOCKOUT_THRESHOLD" check:"positive"`
+	IPLockoutThreshold   int           `env:"LOGIN_IP_LOCKOUT_THRESHOLD" check:"positive"`
+	LockoutDuration      time.Duration `env:"LOGIN_LOCKOUT_DURATION" check:"positive"`
+	FailureWindow        time.Duration `env:"LOGIN_FAILURE_WINDOW" check:"positive"`
+}
+
+// Health holds the readiness check settings.
+type Health struct {
+	CacheTTL        time.Duration `env:"HEALTH_CACHE_TTL" check:"positive"`            // readiness is re-checked at most this often
+	CheckTimeout    time.Duration `env:"HEALTH_CHECK_TIMEOUT" check:"positive"`        // per dependency
+	CertMinValidity time.Duration `env:"HEALTH_CERT_MIN_VALIDITY" check:"nonnegative"` // not ready when the certificate expires sooner
+}
+
+// TOTP holds the authenticator app settings.
+type TOTP struct {
+	Issuer string `env:"TOTP_ISSUER" check:"required"`
+}
+
+// OTP holds the one-time password policy and delivery settings.
+type OTP struct {
+	Length            int           `env:"OTP_LENGTH" check:"positive"`
+	Validity          time.Duration `env:"OTP_VALIDITY" check:"positive"`
+	MaxVerifyAttempts int           `env:"OTP_MAX_VERIFY_ATTEMPTS" check:"positive"`
+	ResendMinGap      time.Duration `env:"OTP_RESEND_MIN_GAP" check:"positive"`
+	MaxResends        int           `env:"OTP_MAX_RESENDS" check:"positive"`
+	LockoutThreshold  int           `env:"OTP_LOCKOUT_THRESHOLD" check:"positive"`
+	LockoutDuration   time.Duration `env:"OTP_LOCKOUT_DURATION" check:"positive"`
+
+	Sender   string `env:"OTP_SENDER" check:"required"` // http, or log, memory or spool with AllowDevSenders
+	SpoolDir string `env:"OTP_SPOOL_DIR"`
+
+	// AllowDevSenders permits the log, memory and spool senders, which keep
+	// codes in plain text. It must never be set in production.
+	AllowDevSenders bool `env:"OTP_ALLOW_DEV_SENDERS"`
+	SMS             SMS
+}
+
+// SMS holds the HTTP SMS gateway settings used when OTP_SENDER is http.
+type SMS struct {
+	Method       string        `env:"OTP_SMS_METHOD"`
+	URL          string        `env:"OTP_SMS_URL" secret:"true"` // usually carries the gateway key
+	Body         string        `env:"OTP_SMS_BODY"`
+	ContentType  string        `env:"OTP_SMS_CONTENT_TYPE"`
+	Message      string        `env:"OTP_SMS_MESSAGE"`
+	TemplateID   string        `env:"OTP_SMS_TEMPLATE_ID"`
+	SenderID     string        `env:"OTP_SMS_SENDER_ID"`
+	SuccessMatch string        `env:"OTP_SMS_SUCCESS_MATCH"`
+	MaxAttempts  int           `env:"OTP_SMS_MAX_ATTEMPTS" check:"positive"`
+	Timeout      time.Duration `env:"OTP_SMS_TIMEOUT" check:"positive"`
+}
+
+// Directory holds the login directory settings.
+type Directory struct {
+	Provider    string `env:"DIRECTORY_PROVIDER"`                   // ldap or memory
+	MemoryUsers string `env:"DIRECTORY_MEMORY_USERS" secret:"true"` // username:password:ou;...
+	LDAP        LDAP
+}
+
+// LDAP holds the LDAP directory settings.
+type LDAP struct {
+	URLs             []string      `env:"LDAP_URLS"`
+	BindDN           string        `env:"LDAP_BIND_DN"`
+	BindPassword     string        `env:"LDAP_BIND_PASSWORD" secret:"true"`
+	OUs              string        `env:"LDAP_OUS"` // name=baseDN;...
+	UserFilter       string        `env:"LDAP_USER_FILTER"`
+	Attributes       string        `env:"LDAP_ATTRIBUTES"`
+	TLSMode          string        `env:"LDAP_TLS_MODE"`
+	CAFile           string        `env:"LDAP_CA_FILE"`
+	PoolSize         int           `env:"LDAP_POOL_SIZE" check:"positive"`
+	DialTimeout      time.Duration `env:"LDAP_DIAL_TIMEOUT" check:"positive"`
+	RequestTimeout   time.Duration `env:"LDAP_TIMEOUT" check:"positive"`
+	HealthCheckAfter time.Duration `env:"LDAP_HEALTH_CHECK_AFTER" check:"positive"`
+	BreakerThreshold int           `env:"LDAP_BREAKER_THRESHOLD" check:"positive"`
+	BreakerCooldown  time.Duration `env:"LDAP_BREAKER_COOLDOWN" check:"positive"`
+}
+
+// Databases holds the credentials of each logical database and the pool
+// limits. The credential variables keep their historical names; like the
+// connection strings before them, hosts, users and names are never logged.
+type Databases struct {
+	HRServer   string       `env:"serverhr" secret:"true"`
+	HRUser     string       `env:"userIdhr" secret:"true"`
+	HRPassword string       `env:"passwordhr" secret:"true"`
+	HRName     string       `env:"databasehr" secret:"true"`
+	HRPort     string       `env:"porthr"`
+	HRPool     PoolOverride `envprefix:"DB_POOL_HR_"`
+
+	MeivanServer   string       `env:"serverm" secret:"true"`
+	MeivanUser     string       `env:"userIdm" secret:"true"`
+	MeivanPassword string       `env:"passwordm" secret:"true"`
+	MeivanName     string       `env:"databasem" secret:"true"`
+	MeivanPort     string       `env:"portm"`
+	MeivanPool     PoolOverride `envprefix:"DB_POOL_MEIVAN_"`
+
+	APIValidationServer   string       `env:"DB_HOST" secret:"true"`
+	APIValidationUser     string       `env:"DB_USER" secret:"true"`
+	APIValidationPassword string       `env:"DB_PASSWORD" secret:"true"`
+	APIValidationName     string       `env:"DB_NAME" secret:"true"`
+	APIValidationPort     string       `env:"DB_PORT"`
+	APIValidationPool     PoolOverride `envprefix:"DB_POOL_API_VALIDATION_"`
+
+	HRMySQLServer   string       `env:"DB_HOST_HR" secret:"true"`
+	HRMySQLUser     string       `env:"DB_USER_HR" secret:"true"`
+	HRMySQLPassword string       `env:"DB_PASSWORD_HR" secret:"true"`
+	HRMySQLName     string       `env:"DB_NAME_HR" secret:"true"`
+	HRMySQLPort     string       `env:"DB_PORT_HR"`
+	HRMySQLPool     PoolOverride `envprefix:"DB_POOL_HR_MYSQL_"`
+
+	Pool Pool
+}
+
+// Pool holds the limits applied to every pool.
+type Pool struct {
+	MaxOpen         int           `env:"DB_POOL_MAX_OPEN" check:"nonnegative"`
+	MaxIdle         int           `env:"DB_POOL_MAX_IDLE" check:"nonnegative"`
+	ConnMaxLifetime time.Duration `env:"DB_POOL_CONN_MAX_LIFETIME" check:"nonnegative"`
+	ConnMaxIdleTime time.Duration `env:"DB_POOL_CONN_MAX_IDLE_TIME" check:"nonnegative"`
+}
+
+// PoolOverride holds per-database pool limits; nil fields use Pool.
+// The names are prefixed, e.g. DB_POOL_MEIVAN_MAX_OPEN.
+type PoolOverride struct {
+	MaxOpen         *int           `env:"MAX_OPEN" check:"nonnegative"`
+	MaxIdle         *int           `env:"MAX_IDLE" check:"nonnegative"`
+	ConnMaxLifetime *time.Duration `env:"CONN_MAX_LIFETIME" check:"nonnegative"`
+	ConnMaxIdleTime *time.Duration `env:"CONN_MAX_IDLE_TIME" check:"nonnegative"`
+}
+
+// With returns p with the fields set in o replaced.
+func (p Pool) With(o PoolOverride) Pool {
+	if o.MaxOpen != nil {
+		p.MaxOpen = *o.MaxOpen
+	}
+	if o.MaxIdle != nil {
+		p.MaxIdle = *o.MaxIdle
+	}
+	if o.ConnMaxLifetime != nil {
+		p.ConnMaxLifetime = *o.ConnMaxLifetime
+	}
+	if o.ConnMaxIdleTime != nil {
+		p.ConnMaxIdleTime = *o.ConnMaxIdleTime
+	}
+	return p
+}
+
+// Defaults returns the configuration used for settings that are not set.
+func Defaults() *Config {
+	return &Config{
+		Server: Server{
+			Port:        "5000",
+			TLSCertFile: "certificate.pem",
+			TLSKeyFile:  "key.pem",
+
+			TLSReloadInterval: time.Minute,
+
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Tokens: Tokens{
+			AccessTTL:        15 * time.Minute,
+			RefreshTTL:       12 * time.Hour,
+			PreAuthTTL:       5 * time.Minute,
+			ImpersonationTTL: 15 * time.Minute,
+		},
+		Roles: Roles{
+			AdminRoleNames: []string{"Admin"},
+			CacheTTL:       5 * time.Minute,
+		},
+		Sessions: Sessions{
+			CacheTTL:          30 * time.Second,
+			DefaultPolicy:     "1:evict",
+			IdleTimeout:       30 * time.Minute,
+			ReaperInterval:    time.Minute,
+			ActivityFlushTime: 30 * time.Second,
+		},
+		Impersonation: Impersonation{
+			AllowedRoutes: []string{"/TaskInbox", "/Defaultrole", "/Statusmaster"},
+		},
+		APIKeys: APIKeys{
+			CacheTTL:    time.Minute,
+			DefaultTTL:  365 * 24 * time.Hour,
+			RotateGrace: 24 * time.Hour,
+		},
+		LoginThrottle: LoginThrottle{
+			BackoffAfter:         3,
+			BackoffBase:          2 * time.Second,
+			BackoffMax:           5 * time.Minute,
+			UserLockoutThreshold: 10,
+			IPLockoutThreshold:   50,
+			LockoutDuration:      30 * time.Minute,
+			FailureWindow:        time.Hour,
+		},
+		TOTP: TOTP{Issuer: "IITM HR"},
+		Health: Health{
+			CacheTTL:        10 * time.Second,
+			CheckTimeout:    3 * time.Second,
+			CertMinValidity: 7 * 24 * time.Hour,
+		},
+		OTP: OTP{
+			Length:            6,
+			Validity:          45 * time.Second,
+			MaxVerifyAttempts: 3,
+			ResendMinGap:      30 * time.Second,
+			MaxResends:        3,
+			LockoutThreshold:  10,
+			LockoutDuration:   15 * time.Minute,
+			SMS: SMS{
+				Method:      "GET",
+				ContentType: "application/x-www-form-urlencoded",
+				MaxAttempts: 3,
+				Timeout:     10 * time.Second,
+			},
+		},
+		Directory: Directory{
+			Provider: "ldap",
+			LDAP: LDAP{
+				TLSMode:          "starttls",
+				PoolSize:         5,
+				DialTimeout:      5 * time.Second,
+				RequestTimeout:   10 * time.Second,
+				HealthCheckAfter: 30 * time.Second,
+				BreakerThreshold: 3,
+				BreakerCooldown:  30 * time.Second,
+			},
+		},
+		Databases: Databases{
+			Pool: Pool{
+				MaxOpen:         25,
+				MaxIdle:         10,
+				ConnMaxLifetime: 30 * time.Minute,
+				ConnMaxIdleTime: 5 * time.Minute,
+			},
+		},
+	}
+}
@@ -0,0 +1,258 @@
+// Package config reads the Config fields by their env tags.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Errors is the list of problems found while loading the configuration.
+type Errors []error
+
+// Error lists every problem, one per line.
+func (e Errors) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, err := range e {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Load returns Defaults overridden by file (optional, .yaml, .yml or
+// .toml), then by .env in the working directory (optional), then by the
+// process environment. All problems are returned together as Errors.
+// A setting left empty keeps the value of the next source.
+//
+// In file, a setting is named like its variable, in any case; nested
+// tables are joined with "_", so session: {idle_timeout: 30m} is
+// SESSION_IDLE_TIMEOUT.
+func Load(file string) (*Config, error) {
+	var fileValues map[string]string
+	if file != "" {
+		var err error
+		if fileValues, err = readFile(file); err != nil {
+			return nil, Errors{err}
+		}
+	}
+
+	dotenv, err := godotenv.Read(".env")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, Errors{fmt.Errorf(".env: %v", err)}
+	}
+
+	// An empty value counts as unset, as it did with os.Getenv.
+	lookup := func(name string) (string, bool) {
+		for _, v := range []string{os.Getenv(name), dotenv[name], fileValues[strings.ToUpper(name)]} {
+			if strings.TrimSpace(v) != "" {
+				return v, true
+			}
+		}
+		return "", false
+	}
+
+	cfg := Defaults()
+	var errs Errors
+	walk(reflect.ValueOf(cfg).Elem(), "", func(name string, f reflect.Value, field reflect.StructField) {
+		if v, ok := lookup(name); ok {
+			if err := set(f, v); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", name, err))
+				return
+			}
+		}
+		if err := check(f, field.Tag.Get("check")); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", name, err))
+		}
+	})
+	errs = append(errs, cfg.validate()...)
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return cfg, nil
+}
+
+// walk calls fn for every tagged field below v. Nested structs are walked;
+// an envprefix tag is prepended to the names of the fields below it.
+func walk(v reflect.Value, prefix string, fn func(name string, f reflect.Value, field reflect.StructField)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name, ok := field.Tag.Lookup("env"); ok {
+			fn(prefix+name, v.Field(i), field)
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			walk(v.Field(i), prefix+field.Tag.Get("envprefix"), fn)
+		}
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// set parses s into f according to the field's type.
+func set(f reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	if f.Kind() == reflect.Pointer {
+		if s == "" {
+			return nil
+		}
+		p := reflect.New(f.Type().Elem())
+		if err := set(p.Elem(), s); err != nil {
+			return err
+		}
+		f.Set(p)
+		return nil
+	}
+
+	switch {
+	case f.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 30s or 15m, got %q", s)
+		}
+		f.SetInt(int64(d))
+	case f.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("must be a whole number, got %q", s)
+		}
+		f.SetInt(int64(n))
+	case f.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", s)
+		}
+		f.SetBool(b)
+	case f.Kind() == reflect.String:
+		f.SetString(s)
+	case f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.String:
+		var list []string
+		for _, part := range strings.Split(s, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				list = append(list, part)
+			}
+		}
+		f.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported setting type %s", f.Type())
+	}
+	return nil
+}
+
+// check applies the check tag: required, positive or nonnegative.
+func check(f reflect.Value, rule string) error {
+	if f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			return nil
+		}
+		f = f.Elem()
+	}
+	switch rule {
+	case "required":
+		if f.Len() == 0 {
+			return errors.New("must be set")
+		}
+	case "positive":
+		if f.Int() <= 0 {
+			return errors.New("must be greater than 0")
+		}
+	case "nonnegative":
+		if f.Int() < 0 {
+			return errors.New("must not be negative")
+		}
+	}
+	return nil
+}
+
+// readFile reads a YAML or TOML file into upper-cased setting names.
+func readFile(file string) (map[string]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %v", err)
+	}
+
+	var tree map[string]interface{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s: want a .yaml, .yml or .toml file", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %v", file, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", tree, values)
+	return values, nil
+}
+
+// flatten stores the scalars of tree under their joined, upper-cased names.
+// Lists become comma separated values.
+func flatten(prefix string, tree map[string]interface{}, out map[string]string) {
+	for k, v := range tree {
+		name := strings.ToUpper(prefix + k)
+		switch v := v.(type) {
+		case map[string]interface{}:
+			flatten(name+"_", v, out)
+		case []interface{}:
+			parts := make([]string, len(v))
+			for i, item := range v {
+				parts[i] = fmt.Sprint(item)
+			}
+			out[name] = strings.Join(parts, ",")
+		default:
+			out[name] = fmt.Sprint(v)
+		}
+	}
+}
+
+// Summary returns every setting as NAME=value, sorted by name, with secret
+// values replaced by ****. It is safe to log.
+func (c *Config) Summary() []string {
+	var lines []string
+	walk(reflect.ValueOf(c).Elem(), "", func(name string, f reflect.Value, field reflect.StructField) {
+		value := ""
+		switch {
+		case f.Kind() == reflect.Pointer && f.IsNil():
+		case field.Tag.Get("secret") == "true":
+			if !f.IsZero() {
+				value = "****"
+			}
+		case f.Kind() == reflect.Pointer:
+			value = fmt.Sprint(f.Elem().Interface())
+		case f.Kind() == reflect.Slice:
+			value = strings.Join(f.Interface().([]string), ",")
+		default:
+			value = fmt.Sprint(f.Interface())
+		}
+		lines = append(lines, name+"="+value)
+	})
+	sort.Strings(lines)
+	return lines
+}
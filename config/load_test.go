@@ -0,0 +1,209 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testEncryptionKey = "0123456789abcdef0123456789abcdef"
+
+// isolate runs the test in an empty directory with every setting unset, then
+// sets the few that have no usable default.
+func isolate(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	walk(reflect.ValueOf(Defaults()).Elem(), "", func(name string, f reflect.Value, field reflect.StructField) {
+		t.Setenv(name, "")
+	})
+	for name, value := range map[string]string{
+		"ENCRYPTION_KEY":        testEncryptionKey,
+		"JWT_KEYS":              "k1=secret",
+		"JWT_SIGNING_KEY_ID":    "k1",
+		"OTP_SENDER":            "memory",
+		"OTP_ALLOW_DEV_SENDERS": "true",
+		"DIRECTORY_PROVIDER":    "memory",
+		"SERVER_BEHIND_PROXY":   "true",
+	} {
+		t.Setenv(name, value)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string // SERVER_PORT in config.yaml
+		dotenv string // SERVER_PORT in .env
+		env    string // SERVER_PORT in the environment
+		want   string
+	}{
+		{name: "default", want: "5000"},
+		{name: "file beats default", file: "5001", want: "5001"},
+		{name: ".env beats file", file: "5001", dotenv: "5002", want: "5002"},
+		{name: "environment beats .env", file: "5001", dotenv: "5002", env: "5003", want: "5003"},
+		{name: "environment beats file", file: "5001", env: "5003", want: "5003"},
+		{name: "empty environment value is unset", file: "5001", dotenv: "5002", env: " ", want: "5002"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			file := ""
+			if tt.file != "" {
+				file = filepath.Join(t.TempDir(), "config.yaml")
+				writeFile(t, file, "server:\n  port: "+tt.file+"\n")
+			}
+			if tt.dotenv != "" {
+				writeFile(t, ".env", "SERVER_PORT="+tt.dotenv+"\n")
+			}
+			t.Setenv("SERVER_PORT", tt.env)
+
+			cfg, err := Load(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Server.Port != tt.want {
+				t.Errorf("SERVER_PORT = %q, want %q", cfg.Server.Port, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileFormats(t *testing.T) {
+	tests := []struct {
+		name, content string
+	}{
+		{"config.yaml", "session:\n  idle_timeout: 45m\ncors_allowed_origins:\n  - https://a.example\n  - https://b.example\n"},
+		{"config.toml", "cors_allowed_origins = [\"https://a.example\", \"https://b.example\"]\n[session]\nidle_timeout = \"45m\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			file := filepath.Join(t.TempDir(), tt.name)
+			writeFile(t, file, tt.content)
+
+			cfg, err := Load(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Sessions.IdleTimeout != 45*time.Minute {
+				t.Errorf("SESSION_IDLE_TIMEOUT = %v, want 45m", cfg.Sessions.IdleTimeout)
+			}
+			want := []string{"https://a.example", "https://b.example"}
+			if !reflect.DeepEqual(cfg.Server.CORSOrigins, want) {
+				t.Errorf("CORS_ALLOWED_ORIGINS = %q, want %q", cfg.Server.CORSOrigins, want)
+			}
+		})
+	}
+
+	isolate(t)
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, file, "{}")
+	if _, err := Load(file); err == nil {
+		t.Error("Load(config.json) succeeded, want an unsupported format error")
+	}
+}
+
+func TestLoadAggregatesErrors(t *testing.T) {
+	isolate(t)
+	t.Setenv("ENCRYPTION_KEY", "too short")
+	t.Setenv("OTP_LENGTH", "six")
+	t.Setenv("SESSION_IDLE_TIMEOUT", "forever")
+	t.Setenv("LOGIN_BACKOFF_AFTER", "-1")
+	t.Setenv("JWT_SIGNING_KEY_ID", "")
+
+	_, err := Load("")
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Load error = %v, want Errors", err)
+	}
+	for _, name := range []string{"ENCRYPTION_KEY", "OTP_LENGTH", "SESSION_IDLE_TIMEOUT", "LOGIN_BACKOFF_AFTER", "JWT_SIGNING_KEY_ID"} {
+		if !strings.Contains(err.Error(), name+":") {
+			t.Errorf("errors do not mention %s:\n%v", name, err)
+		}
+	}
+	if strings.Contains(err.Error(), "too short") {
+		t.Errorf("errors print the ENCRYPTION_KEY value:\n%v", err)
+	}
+	if len(errs) < 5 {
+		t.Errorf("got %d errors, want one per bad setting:\n%v", len(errs), err)
+	}
+}
+
+func TestSummaryMasksSecrets(t *testing.T) {
+	isolate(t)
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("TOTP_ISSUER", "Example HR")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := cfg.Summary()
+	summary := strings.Join(lines, "\n")
+
+	for _, secret := range []string{testEncryptionKey, "hunter2"} {
+		if strings.Contains(summary, secret) {
+			t.Errorf("Summary contains the secret %q", secret)
+		}
+	}
+	for _, want := range []string{
+		"ENCRYPTION_KEY=****",
+		"DB_PASSWORD=****",
+		"LDAP_BIND_PASSWORD=", // unset secrets stay empty
+		"TOTP_ISSUER=Example HR",
+		"SERVER_PORT=5000",
+	} {
+		found := false
+		for _, line := range lines {
+			if line == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Summary has no line %q", want)
+		}
+	}
+}
+
+func TestLoadCORSOrigins(t *testing.T) {
+	tests := []struct {
+		origins string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"https://hr.example.edu, https://admin.example.edu", []string{"https://hr.example.edu", "https://admin.example.edu"}, false},
+		{"*", nil, true},
+		{"https://hr.example.edu,*", nil, true},
+	}
+	for _, tt := range tests {
+		isolate(t)
+		t.Setenv("CORS_ALLOWED_ORIGINS", tt.origins)
+
+		cfg, err := Load("")
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "CORS_ALLOWED_ORIGINS:") {
+				t.Errorf("CORS_ALLOWED_ORIGINS=%q: error = %v, want a CORS_ALLOWED_ORIGINS error", tt.origins, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("CORS_ALLOWED_ORIGINS=%q: %v", tt.origins, err)
+			continue
+		}
+		if !reflect.DeepEqual(cfg.Server.CORSOrigins, tt.want) {
+			t.Errorf("CORS_ALLOWED_ORIGINS=%q = %q, want %q", tt.origins, cfg.Server.CORSOrigins, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Package config checks the settings that depend on each other.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// validate returns the problems that the check tags cannot express.
+func (c *Config) validate() Errors {
+	var errs Errors
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if n := len(c.Security.EncryptionKey); n != 0 && n != 32 {
+		add("ENCRYPTION_KEY: must be 32 bytes, got %d", n)
+	}
+
+	if !c.Server.BehindProxy && (c.Server.TLSCertFile == "" || c.Server.TLSKeyFile == "") {
+		add("TLS_CERT_FILE and TLS_KEY_FILE: must be set unless SERVER_BEHIND_PROXY is true")
+	}
+	if c.Server.MTLSPort != "" {
+		if c.Server.MTLSClientCAFile == "" {
+			add("MTLS_CLIENT_CA_FILE: must be set when MTLS_PORT is set")
+		}
+		if c.Server.TLSCertFile == "" || c.Server.TLSKeyFile == "" {
+			add("TLS_CERT_FILE and TLS_KEY_FILE: must be set when MTLS_PORT is set")
+		}
+		if c.Server.MTLSPort == c.Server.Port {
+			add("MTLS_PORT: must differ from SERVER_PORT")
+		}
+	}
+	for _, origin := range c.Server.CORSOrigins {
+		if origin == "*" {
+			add("CORS_ALLOWED_ORIGINS: \"*\" cannot be used because credentials are allowed; list the origins")
+		}
+	}
+	if len(c.Server.TrustedProxies) > 0 && !c.Server.BehindProxy {
+		add("TRUSTED_PROXIES: only used when SERVER_BEHIND_PROXY is true")
+	}
+
+	if c.OTP.Length < 4 || c.OTP.Length > 10 {
+		add("OTP_LENGTH: must be between 4 and 10")
+	}
+	switch c.OTP.Sender {
+	case "":
+		// reported by the required check
+	case "log", "memory", "spool":
+		if !c.OTP.AllowDevSenders {
+			add("OTP_SENDER: %s keeps codes in plain text; set OTP_ALLOW_DEV_SENDERS=true to use it in development", c.OTP.Sender)
+		}
+		if c.OTP.Sender == "spool" && c.OTP.SpoolDir == "" {
+			add("OTP_SPOOL_DIR: must be set when OTP_SENDER is spool")
+		}
+	case "http":
+		if c.OTP.SMS.URL == "" {
+			add("OTP_SMS_URL: must be set when OTP_SENDER is http")
+		}
+		if m := strings.ToUpper(c.OTP.SMS.Method); m != "GET" && m != "POST" {
+			add("OTP_SMS_METHOD: must be GET or POST, got %q", c.OTP.SMS.Method)
+		}
+	default:
+		add("OTP_SENDER: must be log, memory, spool or http, got %q", c.OTP.Sender)
+	}
+
+	switch provider := strings.ToLower(c.Directory.Provider); provider {
+	case "ldap":
+		errs = append(errs, c.Directory.LDAP.validate()...)
+	case "memory":
+	default:
+		add("DIRECTORY_PROVIDER: must be ldap or memory, got %q", c.Directory.Provider)
+	}
+	return errs
+}
+
+// validate checks the LDAP settings needed when the provider is ldap.
+func (l *LDAP) validate() Errors {
+	var errs Errors
+	if len(l.URLs) == 0 {
+		errs = append(errs, errors.New("LDAP_URLS: must be set when DIRECTORY_PROVIDER is ldap"))
+	}
+	if l.BindDN == "" || l.BindPassword == "" {
+		errs = append(errs, errors.New("LDAP_BIND_DN and LDAP_BIND_PASSWORD: must be set when DIRECTORY_PROVIDER is ldap"))
+	}
+	if l.UserFilter != "" && !strings.Contains(l.UserFilter, "{username}") {
+		errs = append(errs, errors.New("LDAP_USER_FILTER: must contain {username}"))
+	}
+	switch strings.ToLower(l.TLSMode) {
+	case "starttls", "ldaps", "none":
+	default:
+		errs = append(errs, fmt.Errorf("LDAP_TLS_MODE: must be starttls, ldaps or none, got %q", l.TLSMode))
+	}
+	return errs
+}
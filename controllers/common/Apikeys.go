@@ -0,0 +1,266 @@
+// Package controllerscommon provides the admin endpoints that issue, rotate
+// and revoke the API keys checked by auth.ValidateAPI.
+//
+// It ensures:
+//   - Only holders of auth.PermAPIKeyManage can call them
+//   - A new key is returned once, in the response; only its hash is stored
+//   - A rotated key keeps working for API_KEY_ROTATE_GRACE so the vendor can
+//     switch over; a revoked key stops at once
+//   - The key cache of this instance is cleared after every change
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerscommon
+
+import (
+	"Hrmodule/auth"
+	"Hrmodule/config"
+	database "Hrmodule/database/common"
+	"Hrmodule/utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// apiKeyDefaultTTL is the lifetime of a key issued without expires_in_days;
+// 0 issues keys that do not expire.
+var apiKeyDefaultTTL = 365 * 24 * time.Hour
+
+// apiKeyRotateGrace is how long a rotated key keeps working.
+var apiKeyRotateGrace = 24 * time.Hour
+
+// Configure applies API_KEY_DEFAULT_TTL and API_KEY_ROTATE_GRACE. It is
+// called once from main before the server starts.
+func Configure(cfg *config.Config) error {
+	apiKeyDefaultTTL = cfg.APIKeys.DefaultTTL
+	apiKeyRotateGrace = cfg.APIKeys.RotateGrace
+	return nil
+}
+
+// APIResponseforApiKey defines the standard structure of the API key responses.
+type APIResponseforApiKey struct {
+	Status  int         `json:"Status"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"Data"`
+}
+
+// ApiKeyRequest represents the request body of /ApiKeyIssue, /ApiKeyRotate and /ApiKeyRevoke
+type ApiKeyRequest struct {
+	Token         string `json:"token"`
+	VendorId      int64  `json:"vendor_id"`       // /ApiKeyIssue
+	KeyId         int64  `json:"key_id"`          // /ApiKeyRotate, /ApiKeyRevoke
+	ExpiresInDays *int   `json:"expires_in_days"` // optional; 0 for no expiry
+}
+
+// ttl returns the lifetime requested for a new key.
+func (req ApiKeyRequest) ttl() (time.Duration, bool) {
+	if req.ExpiresInDays == nil {
+		return apiKeyDefaultTTL, true
+	}
+	if *req.ExpiresInDays < 0 {
+		return 0, false
+	}
+	return time.Duration(*req.ExpiresInDays) * 24 * time.Hour, true
+}
+
+// expiresOn formats the expiry of a key issued now with ttl, empty for none.
+func expiresOn(ttl time.Duration) string {
+	if ttl == 0 {
+		return ""
+	}
+	return time.Now().Add(ttl).Format(time.RFC3339)
+}
+
+// ApiKeyIssueHandler handles POST /ApiKeyIssue. It issues a new key for an
+// active vendor.
+func ApiKeyIssueHandler(w http.ResponseWriter, r *http.Request) {
+	serveApiKeyRequest(w, r, func(w http.ResponseWriter, r *http.Request, req ApiKeyRequest) {
+		if req.VendorId <= 0 {
+			http.Error(w, "Missing required field: vendor_id", http.StatusBadRequest)
+			return
+		}
+		ttl, ok := req.ttl()
+		if !ok {
+			http.Error(w, "expires_in_days must not be negative", http.StatusBadRequest)
+			return
+		}
+
+		key, keyHash, prefix, err := auth.NewAPIKey()
+		if err != nil {
+			log.Printf("Error generating API key: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		username := auth.UsernameFromContext(r.Context())
+		keyId, err := database.InsertAPIKey(req.VendorId, keyHash, prefix, username, ttl)
+		switch {
+		case errors.Is(err, database.ErrVendorNotFound):
+			http.Error(w, "Vendor not found", http.StatusNotFound)
+			return
+		case errors.Is(err, database.ErrVendorInactive):
+			http.Error(w, "Vendor is not active", http.StatusConflict)
+			return
+		case err != nil:
+			log.Printf("Error issuing API key: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		auth.InvalidateAPIKeys()
+
+		log.Printf("API key %d (%s) issued for vendor %d by %s", keyId, prefix, req.VendorId, username)
+		sendApiKeyResponse(w, http.StatusOK, "API key issued", map[string]interface{}{
+			"key_id":     keyId,
+			"vendor_id":  req.VendorId,
+			"key":        key,
+			"key_prefix": prefix,
+			"expires_on": expiresOn(ttl),
+		})
+	})
+}
+
+// ApiKeyRotateHandler handles POST /ApiKeyRotate. It issues a replacement
+// for an active key; the old key expires after the grace period.
+func ApiKeyRotateHandler(w http.ResponseWriter, r *http.Request) {
+	serveApiKeyRequest(w, r, func(w http.ResponseWriter, r *http.Request, req ApiKeyRequest) {
+		if req.KeyId <= 0 {
+			http.Error(w, "Missing required field: key_id", http.StatusBadRequest)
+			return
+		}
+		ttl, ok := req.ttl()
+		if !ok {
+			http.Error(w, "expires_in_days must not be negative", http.StatusBadRequest)
+			return
+		}
+
+		key, keyHash, prefix, err := auth.NewAPIKey()
+		if err != nil {
+			log.Printf("Error generating API key: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		username := auth.UsernameFromContext(r.Context())
+		newId, vendorId, err := database.RotateAPIKey(req.KeyId, keyHash, prefix, username, ttl, apiKeyRotateGrace)
+		if errors.Is(err, database.ErrAPIKeyNotFound) {
+			http.Error(w, "API key not found or not active", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("Error rotating API key: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		auth.InvalidateAPIKeys()
+
+		log.Printf("API key %d rotated to %d (%s) for vendor %d by %s", req.KeyId, newId, prefix, vendorId, username)
+		sendApiKeyResponse(w, http.StatusOK, "API key rotated", map[string]interface{}{
+			"key_id":          newId,
+			"vendor_id":       vendorId,
+			"key":             key,
+			"key_prefix":      prefix,
+			"expires_on":      expiresOn(ttl),
+			"replaced_key_id": req.KeyId,
+			"grace_seconds":   int64(apiKeyRotateGrace.Seconds()),
+		})
+	})
+}
+
+// ApiKeyRevokeHandler handles POST /ApiKeyRevoke. The key stops working at once.
+func ApiKeyRevokeHandler(w http.ResponseWriter, r *http.Request) {
+	serveApiKeyRequest(w, r, func(w http.ResponseWriter, r *http.Request, req ApiKeyRequest) {
+		if req.KeyId <= 0 {
+			http.Error(w, "Missing required field: key_id", http.StatusBadRequest)
+			return
+		}
+
+		username := auth.UsernameFromContext(r.Context())
+		err := database.RevokeAPIKey(req.KeyId, username)
+		if errors.Is(err, database.ErrAPIKeyNotFound) {
+			http.Error(w, "API key not found or not active", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("Error revoking API key: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		auth.InvalidateAPIKeys()
+
+		log.Printf("API key %d revoked by %s", req.KeyId, username)
+		sendApiKeyResponse(w, http.StatusOK, "API key revoked", map[string]interface{}{
+			"key_id": req.KeyId,
+		})
+	})
+}
+
+// serveApiKeyRequest runs the common steps of the API key endpoints:
+// method check, body parsing, API validation and request logging.
+func serveApiKeyRequest(w http.ResponseWriter, r *http.Request, serve func(http.ResponseWriter, *http.Request, ApiKeyRequest)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed, use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Unable to read request body", http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	var req ApiKeyRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "Invalid JSON input", http.StatusBadRequest)
+		return
+	}
+
+	// If token provided in body, inject into header
+	if req.Token != "" {
+		r.Header.Set("token", req.Token)
+	}
+	if !auth.HandleRequestfor_apiname_ipaddress_token(w, r) {
+		return
+	}
+
+	loggedHandler := auth.LogRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, req)
+	}))
+	loggedHandler.ServeHTTP(w, r)
+}
+
+// sendApiKeyResponse sends an encrypted APIResponseforApiKey.
+func sendApiKeyResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	jsonResponse, err := json.MarshalIndent(APIResponseforApiKey{
+		Status:  statusCode,
+		Message: message,
+		Data:    data,
+	}, "", "    ")
+	if err != nil {
+		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	encrypted, err := utils.Encrypt(jsonResponse)
+	if err != nil {
+		http.Error(w, "Encryption failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"Data": encrypted,
+	})
+}
@@ -9,7 +9,7 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 30-07-2025
+// Last Modified Date: 17-10-2026
 package controllerscommon
 
 import (
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 )
 
 // APIResponseforDefaultRoleName defines the standard structure of the API response.
@@ -31,8 +32,8 @@ type APIResponseforDefaultRoleName struct {
 
 // Struct for request body (for token injection + flexibility for other fields later)
 type DefaultRoleNameRequest struct {
-	Token string `json:"token"`
-	// Add more request params here if needed in future
+	Token    string `json:"token"`
+	UserName string `json:"UserName"`
 }
 
 // DefaultRoleName handles the HTTP POST request to fetch DefaultRoleName data for Employees.
@@ -71,14 +72,20 @@ func DefaultRoleName(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Step 6: DB query
+		// Step 6: Only admins may look up roles of another user
+		if !strings.EqualFold(req.UserName, auth.UsernameFromContext(r.Context())) && !auth.Can(r.Context(), auth.PermRolesReadAny) {
+			auth.RespondForbidden(w, "Not allowed to view roles of another user")
+			return
+		}
+
+		// Step 7: DB query
 		DefaultRoleNameData, totalCount, err := database.DefaultRoleNamedatabase(w, r)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		// Step 7: Response struct
+		// Step 8: Response struct
 		response := APIResponseforDefaultRoleName{
 			Status:  200,
 			Message: "Success",
@@ -88,27 +95,27 @@ func DefaultRoleName(w http.ResponseWriter, r *http.Request) {
 			},
 		}
 
-		// Step 8: Marshal to JSON
+		// Step 9: Marshal to JSON
 		jsonResponse, err := json.MarshalIndent(response, "", "    ")
 		if err != nil {
 			http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
 			return
 		}
 
-		// Step 9: Encrypt
+		// Step 10: Encrypt
 		encrypted, err := utils.Encrypt(jsonResponse)
 		if err != nil {
 			http.Error(w, "Encryption failed", http.StatusInternalServerError)
 			return
 		}
 
-		// Step 10: Send response
+		// Step 11: Send response
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(map[string]string{
 			"Data": encrypted,
 		})
 	}))
 
-	// Step 11: Execute with logging
+	// Step 12: Execute with logging
 	loggedHandler.ServeHTTP(w, r)
 }
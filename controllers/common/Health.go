@@ -0,0 +1,130 @@
+// Package controllerscommon provides the health endpoints.
+//
+// It ensures:
+//   - /healthz answers 200 while the process is running, without touching
+//     any dependency, for liveness probes
+//   - /readyz answers 200 when every dependency check passes and 503
+//     otherwise, for load balancers; the checks are cached (see health)
+//   - The per-dependency report, with errors and timings, is only served on
+//     /HealthReport to holders of auth.PermHealthView, encrypted like every
+//     other API response
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerscommon
+
+import (
+	"Hrmodule/auth"
+	"Hrmodule/health"
+	"Hrmodule/utils"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// HealthReportRequest represents the request body of /HealthReport
+type HealthReportRequest struct {
+	Token string `json:"token"`
+}
+
+// HealthzHandler handles GET /healthz: the process is alive.
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed, use GET", http.StatusMethodNotAllowed)
+		return
+	}
+	writeProbe(w, http.StatusOK, "ok")
+}
+
+// ReadyzHandler handles GET /readyz: every dependency is usable. Only the
+// overall status is returned; see HealthReportHandler for the details.
+func ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed, use GET", http.StatusMethodNotAllowed)
+		return
+	}
+	report := health.Readiness(r.Context())
+	if !report.Ready() {
+		writeProbe(w, http.StatusServiceUnavailable, report.Status)
+		return
+	}
+	writeProbe(w, http.StatusOK, report.Status)
+}
+
+// writeProbe writes the plain JSON status of a probe; probes cannot
+// decrypt responses.
+func writeProbe(w http.ResponseWriter, statusCode int, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
+
+// HealthReportHandler handles POST /HealthReport: the readiness report with
+// the result of every dependency check.
+func HealthReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed, use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Unable to read request body", http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	var req HealthReportRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "Invalid JSON input", http.StatusBadRequest)
+		return
+	}
+
+	// If token provided in body, inject into header
+	if req.Token != "" {
+		r.Header.Set("token", req.Token)
+	}
+	if !auth.HandleRequestfor_apiname_ipaddress_token(w, r) {
+		return
+	}
+
+	loggedHandler := auth.LogRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := health.Readiness(r.Context())
+		statusCode := http.StatusOK
+		if !report.Ready() {
+			statusCode = http.StatusServiceUnavailable
+		}
+		sendHealthResponse(w, statusCode, report)
+	}))
+	loggedHandler.ServeHTTP(w, r)
+}
+
+// sendHealthResponse sends the encrypted report.
+func sendHealthResponse(w http.ResponseWriter, statusCode int, report health.Report) {
+	jsonResponse, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	encrypted, err := utils.Encrypt(jsonResponse)
+	if err != nil {
+		http.Error(w, "Encryption failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"Data": encrypted,
+	})
+}
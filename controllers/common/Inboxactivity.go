@@ -11,12 +11,12 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 26-08-2025
+// Last Modified Date: 17-10-2026
 package controllerscommon
 
 import (
 	"Hrmodule/auth"
-	credentials "Hrmodule/dbconfig"
+	modelscommon "Hrmodule/models/common"
 	"Hrmodule/utils"
 	"bytes"
 	"database/sql"
@@ -31,11 +31,12 @@ import (
 
 // NOCUpdateRequest represents the expected JSON structure for updating NOC master records.
 type NOCUpdateRequest struct {
-	CoverPageNo string `json:"coverpageno"` // identifier for the record to be updated
-	Badge       *int   `json:"badge"`       // Badge value (int, required)
-	Priority    *int   `json:"priority"`    // Priority value (nullable)
-	Starred     *int   `json:"starred"`     // Starred status: 0 = false, 1 = true (nullable)
-	Token       string `json:"token"`       // Token can also come from request body
+	CoverPageNo  string `json:"coverpageno"`  // identifier for the record to be updated
+	Badge        *int   `json:"badge"`        // Badge value (int, required)
+	Priority     *int   `json:"priority"`     // Priority value (nullable)
+	Starred      *int   `json:"starred"`      // Starred status: 0 = false, 1 = true (nullable)
+	AssignedRole string `json:"assignedrole"` // Role under which the record appears in the caller's inbox
+	Token        string `json:"token"`        // Token can also come from request body
 }
 
 // APIResponse defines the JSON response structure used by API endpoints.
@@ -47,13 +48,7 @@ type APIResponse struct {
 
 // UpdateNOCMaster updates the noc_master table with badge, priority, and starred values.
 func UpdateNOCMaster(coverPageNo string, badge, priority, starred *int) (int64, error) {
-	connectionString := credentials.Getdatabasemeivan()
-
-	db, err := sql.Open("postgres", connectionString)
-	if err != nil {
-		return 0, fmt.Errorf("DB open error: %v", err)
-	}
-	defer db.Close()
+	db := meivanDB
 
 	var setParts []string
 	var args []interface{}
@@ -100,6 +95,22 @@ func UpdateNOCMaster(coverPageNo string, badge, priority, starred *int) (int64,
 	return rowsAffected, nil
 }
 
+// coverPageInInbox reports whether coverPageNo is one of the tasks in the
+// inbox of employeeId for the given role.
+func coverPageInInbox(employeeId, assignedRole, coverPageNo string) (bool, error) {
+	db := meivanDB
+
+	var exists int
+	err := db.QueryRow(modelscommon.MyQueryInboxCoverPage, employeeId, assignedRole, coverPageNo).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("inbox lookup error: %v", err)
+	}
+	return true, nil
+}
+
 // NOCUpdateHandler handles POST requests to the /NOCUpdate endpoint.
 func NOCUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	// Read body (so we can inject token if provided in JSON)
@@ -148,6 +159,23 @@ func NOCUpdateHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Non-admins may only update records that are in their own inbox
+		if !auth.IsAdmin(r.Context()) {
+			if req.AssignedRole == "" {
+				http.Error(w, "Missing required field: assignedrole", http.StatusBadRequest)
+				return
+			}
+			inInbox, err := coverPageInInbox(auth.EmployeeIdFromContext(r.Context()), req.AssignedRole, req.CoverPageNo)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if !inInbox {
+				auth.RespondForbidden(w, "Record is not in your inbox")
+				return
+			}
+		}
+
 		// // FIX: req.Badge is *int; check for nil (not empty string)
 		// if req.Badge == nil {
 		// 	http.Error(w, "Missing required field: badge", http.StatusBadRequest)
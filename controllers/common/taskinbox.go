@@ -8,7 +8,7 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 26-08-2025
+// Last Modified Date: 17-10-2026
 package controllerscommon
 
 import (
@@ -31,6 +31,7 @@ type APIResponseforInboxTasksRole struct {
 // Token wrapper
 type InboxTasksRoleTokenRequest struct {
 	Token string `json:"token"`
+	EmpID string `json:"empid"`
 }
 
 // InboxTasksRole API
@@ -66,6 +67,12 @@ func InboxTasksRole(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Only admins may read another employee's inbox
+		if req.EmpID != auth.EmployeeIdFromContext(r.Context()) && !auth.Can(r.Context(), auth.PermInboxReadAny) {
+			auth.RespondForbidden(w, "Not allowed to view inbox of another employee")
+			return
+		}
+
 		// DB
 		data, total, err := database.InboxTasksRoleDatabase(w, r)
 		if err != nil {
@@ -0,0 +1,29 @@
+// Package controllerslogin applies the loaded configuration to the login,
+// session and second factor handlers.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerslogin
+
+import "Hrmodule/config"
+
+// Configure applies cfg to the package. It is called once from main before
+// the server starts; every problem found is returned together.
+func Configure(cfg *config.Config) error {
+	var errs config.Errors
+	errs = append(errs, configureLogin(cfg)...)
+	errs = append(errs, configureSessionPolicies(cfg)...)
+	errs = append(errs, configureSessionReaper(cfg)...)
+	configureLoginThrottle(cfg)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
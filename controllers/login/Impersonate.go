@@ -0,0 +1,157 @@
+// Package controllerslogin provides admin impersonation ("act as employee").
+//
+// It ensures:
+//   - Only holders of auth.PermImpersonate can call /Impersonate
+//   - The token carries the employee's identity plus the admin as its actor,
+//     lives for IMPERSONATION_TOKEN_TTL and is bound to the admin's session
+//   - Admin accounts, the caller themselves and nested impersonation are refused
+//   - Issuing the token is audited like every request made with it
+//     (see auth/impersonation.go)
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerslogin
+
+import (
+	"Hrmodule/auth"
+	databaselogin "Hrmodule/database/login"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// impersonationTokenTTL is the lifetime of an impersonation token. It is
+// never refreshed; the admin calls /Impersonate again.
+var impersonationTokenTTL = 15 * time.Minute
+
+// ImpersonateRequest represents the request body of /Impersonate
+type ImpersonateRequest struct {
+	Token    string `json:"token"`
+	Username string `json:"username"` // employee to act as
+}
+
+// ImpersonateHandler handles POST /Impersonate. It issues a time-limited
+// JWT with which the calling admin sees the API as the given employee.
+func ImpersonateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed, use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Unable to read request body", http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	var req ImpersonateRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "Invalid JSON input", http.StatusBadRequest)
+		return
+	}
+
+	// If token provided in body, inject into header
+	if req.Token != "" {
+		r.Header.Set("token", req.Token)
+	}
+	if !auth.HandleRequestfor_apiname_ipaddress_token(w, r) {
+		return
+	}
+
+	loggedHandler := auth.LogRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor, ok := auth.ClaimsFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if actor.Actor != nil {
+			auth.RespondForbidden(w, "Already impersonating, use your own token")
+			return
+		}
+
+		target := strings.TrimSpace(req.Username)
+		if target == "" {
+			http.Error(w, "Missing required field: username", http.StatusBadRequest)
+			return
+		}
+		if strings.EqualFold(target, actor.Username) {
+			http.Error(w, "Cannot impersonate yourself", http.StatusBadRequest)
+			return
+		}
+
+		employeeId, _, err := getEmployeeInfo(target)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Employee not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("Error retrieving employee info: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		// An admin's view is not a helpdesk concern and would widen the token
+		isAdmin, err := auth.UserIsAdmin(target)
+		if err != nil {
+			log.Printf("Role lookup failed for %s: %v", target, err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if isAdmin {
+			auth.RespondForbidden(w, "Admin accounts cannot be impersonated")
+			return
+		}
+
+		// The token is only handed out once its issue is on record
+		_, err = databaselogin.InsertImpersonationAudit(databaselogin.ImpersonationAudit{
+			ActorUsername:    actor.Username,
+			ActorEmployeeId:  actor.EmployeeId,
+			SessionId:        actor.Session,
+			TargetUsername:   target,
+			TargetEmployeeId: employeeId,
+			Method:           r.Method,
+			Route:            r.URL.Path,
+			Allowed:          true,
+			ClientIP:         clientIP(r),
+		})
+		if err != nil {
+			log.Printf("Impersonation audit failed: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		claims := auth.NewImpersonationClaims(actor, target, employeeId, impersonationTokenTTL)
+		tokenString, err := auth.SignClaims(claims)
+		if err != nil {
+			log.Printf("Error generating JWT: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("Impersonation started: %s as %s", actor.Username, target)
+		sendEncryptedResponse(w, map[string]interface{}{
+			"success":       true,
+			"message":       "Impersonation token issued",
+			"username":      target,
+			"EmployeeId":    employeeId,
+			"token":         tokenString,
+			"expires_in":    int64(impersonationTokenTTL.Seconds()),
+			"allowed_paths": auth.ImpersonationRoutes(),
+		})
+	}))
+	loggedHandler.ServeHTTP(w, r)
+}
@@ -2,6 +2,10 @@
 // encrypted credential validation, session management,
 // and JWT token generation for secure login workflows.
 //
+// A successful LDAP bind only starts a login transaction and returns a
+// pre-auth token; the session and its JWT are created after the second
+// factor (see Logintransaction.go).
+//
 // --- Creator's Info ---
 //
 // Creator: Sridharan
@@ -10,30 +14,30 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 26-08-2025
+// Last Modified Date: 17-10-2026
 package controllerslogin
 
 import (
 	"Hrmodule/auth"
-	credentials "Hrmodule/dbconfig"
+	"Hrmodule/config"
+	databasecommon "Hrmodule/database/common"
+	"Hrmodule/directory"
+	"Hrmodule/otp"
 	"Hrmodule/utils"
 	"bytes"
+	"context"
 	"crypto/aes"
-	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
-	ldap "github.com/go-ldap/ldap/v3"
 	_ "github.com/go-sql-driver/mysql"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	"github.com/joho/godotenv"
 )
 
 type AuthRequest struct {
@@ -43,50 +47,80 @@ type AuthRequest struct {
 }
 
 type AuthResponse struct {
-	Valid        bool   `json:"valid"`
-	UserId       string `json:"userId,omitempty"`
-	Username     string `json:"username,omitempty"`
-	EmployeeId   string `json:"EmployeeId"`
-	MobileNumber string `json:"MobileNumber"`
-	Token        string `json:"token,omitempty"`
+	Valid           bool   `json:"valid"`
+	UserId          string `json:"userId,omitempty"`
+	Username        string `json:"username,omitempty"`
+	EmployeeId      string `json:"EmployeeId"`
+	MobileNumber    string `json:"MobileNumber"`
+	Token           string `json:"token,omitempty"`
+	RefreshToken    string `json:"refresh_token,omitempty"`    // Opaque token for /TokenRefresh
+	PreAuthToken    string `json:"preauth_token,omitempty"`    // Bearer token for the second-factor endpoints
+	ExpiresIn       int64  `json:"expires_in,omitempty"`       // Lifetime in seconds of the token returned
+	PreferredFactor string `json:"preferred_factor,omitempty"` // "sms" or "totp"; picks /Loginotp or /TotpVerify
 }
 
 type AuthResponsefalse struct {
-	Valid    bool   `json:"valid"`
-	Username string `json:"username,omitempty"`
-	Error    string `json:"error,omitempty"`
+	Valid      bool   `json:"valid"`
+	Username   string `json:"username,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Locked     bool   `json:"locked,omitempty"`      // Lockout threshold reached
+	RetryAfter int    `json:"retry_after,omitempty"` // Seconds until the next attempt is accepted
 }
 
-var jwtSecret []byte
 var encryptionKey string
 
-func init() {
-	// Optional: load from .env file (for development)
-	_ = godotenv.Load()
-
-	jwtKey := os.Getenv("JWT_SECRET_KEY")
-	if jwtKey == "" {
-		panic("JWT_SECRET_KEY environment variable not set")
-	}
-	jwtSecret = []byte(jwtKey)
+// Directory checks the credentials posted to /HRldap. It is selected by
+// DIRECTORY_PROVIDER; tests can replace it with a directory.MemoryDirectory.
+var Directory directory.Authenticator
 
-	encryptionKey = os.Getenv("ENCRYPTION_KEY")
-	if encryptionKey == "" {
-		panic("ENCRYPTION_KEY environment variable not set")
+// PingDirectory checks that the directory is reachable, for the health
+// checks. Directories without servers (MemoryDirectory) always pass.
+func PingDirectory(ctx context.Context) error {
+	if p, ok := Directory.(directory.Pinger); ok {
+		return p.Ping(ctx)
 	}
+	return nil
 }
 
-// Create JWT Token
-func generateJWT(userId, username, employeeId string) (string, error) {
-	claims := jwt.MapClaims{
-		"userId":     userId,
-		"username":   username,
-		"employeeId": employeeId,
-		"exp":        time.Now().Add(time.Hour * 2).Unix(), // Token expires in 2 hours
+// roleRules maps directory attributes to ROLEMASTER roles (ROLE_MAPPING).
+// When empty, roles are not provisioned at login.
+var roleRules []directory.RoleRule
+
+// roleProvisionSource marks the ORGUNITUSERMAPPING rows provisioned at login.
+const roleProvisionSource = "ldap"
+
+// accessTokenTTL is the lifetime of the JWT returned to the client.
+// refreshTokenTTL is the absolute lifetime of a refresh token family;
+// rotation does not extend it, so the user logs in again after it lapses.
+var accessTokenTTL = 15 * time.Minute
+var refreshTokenTTL = 12 * time.Hour
+
+// configureLogin applies ENCRYPTION_KEY, the directory, ROLE_MAPPING and
+// the token lifetimes.
+func configureLogin(cfg *config.Config) config.Errors {
+	var errs config.Errors
+	encryptionKey = cfg.Security.EncryptionKey
+
+	var err error
+	if Directory, err = directory.FromConfig(cfg.Directory); err != nil {
+		errs = append(errs, fmt.Errorf("directory: %v", err))
+	}
+	if roleRules, err = directory.ParseRoleRules(cfg.Roles.Mapping); err != nil {
+		errs = append(errs, fmt.Errorf("ROLE_MAPPING: %v", err))
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	accessTokenTTL = cfg.Tokens.AccessTTL
+	refreshTokenTTL = cfg.Tokens.RefreshTTL
+	preAuthTokenTTL = cfg.Tokens.PreAuthTTL
+	impersonationTokenTTL = cfg.Tokens.ImpersonationTTL
+	totpIssuer = cfg.TOTP.Issuer
+	return errs
+}
+
+// Create JWT Token bound to the session it was issued for
+func generateJWT(userId, username, employeeId, sessionId string) (string, error) {
+	claims := auth.NewClaims(userId, username, employeeId, sessionId, accessTokenTTL)
+	return auth.SignClaims(claims)
 }
 
 // Helper function to check if string is hex-encoded
@@ -161,8 +195,9 @@ func PKCS5Unpad(data []byte) []byte {
 }
 
 // HandleLDAPAuth processes an HTTP request for LDAP authentication.
-// It ONLY accepts encrypted credentials, validates them against LDAP servers (staff, faculty, project),
-// inserts session data into the database, and returns an encrypted JSON response with JWT token.
+// It ONLY accepts encrypted credentials, validates them against the configured
+// Directory (OUs in order, e.g. staff, faculty, project), starts a login
+// transaction and returns an encrypted JSON response with the pre-auth token.
 func HandleLDAPAuth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed. Use POST.", http.StatusMethodNotAllowed)
@@ -188,7 +223,7 @@ func HandleLDAPAuth(w http.ResponseWriter, r *http.Request) {
 	r.Header.Set("token", req.Token)
 
 	// handles sp validation
-	authorized := auth.HandleRequestfor_apiname_ipaddress_token(w, r)
+	authorized := ldapBackend.authorize(w, r)
 	if !authorized {
 		return
 	}
@@ -239,15 +274,7 @@ func HandleLDAPAuth(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Print encrypted credentials for debugging
-		fmt.Println("Encrypted username:", username)
-		fmt.Println("Encrypted password:", password)
-
 		// Decrypt username and password - ONLY accept encrypted data
-		// key := "7xPz!qL3vNc#eRb9Wm@f2Zh8Kd$gYp1B"
-
-		fmt.Println("Decryption key:", encryptionKey)
-
 		// Decrypt username using strict decryption
 		decodedUsername, err := decryptDataStrict(username, encryptionKey)
 		if err != nil {
@@ -274,8 +301,6 @@ func HandleLDAPAuth(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		fmt.Println("Decrypted Username:", decodedUsername)
-
 		// Decrypt password using strict decryption
 		decodedPassword, err := decryptDataStrict(password, encryptionKey)
 		if err != nil {
@@ -301,254 +326,170 @@ func HandleLDAPAuth(w http.ResponseWriter, r *http.Request) {
 			})
 			return
 		}
-		fmt.Println("Decrypted Password:", decodedPassword)
-
-		// Continue with LDAP authentication using decodedUsername and decodedPassword...
-		dn := "cn=academicbind,ou=bind,dc=ldap,dc=iitm,dc=ac,dc=in"
-		pass := "1@iIL~0K"
-		ldapUserFilter := "(&(objectclass=*)(uid=" + decodedUsername + "))"
-		searchBaseStaff := "ou=staff,ou=people,dc=ldap,dc=iitm,dc=ac,dc=in"
-		searchBaseFaculty := "ou=faculty,ou=people,dc=ldap,dc=iitm,dc=ac,dc=in"
-		searchbase_project := "ou=project,ou=employee,dc=ldap,dc=iitm,dc=ac,dc=in"
-		//	searchBaseStudent := "ou=student,dc=ldap,dc=iitm,dc=ac,dc=in"  //comment for later use
-		//fmt.Println(decodedUsername)
-		ldapURL := "ldap://ldap.iitm.ac.in:389"
-
-		conn, err := ldap.DialURL(ldapURL)
+
+		// Refuse throttled usernames and addresses before they reach the directory
+		ip := clientIP(r)
+		attempt, block, err := ldapBackend.reserveAttempt(decodedUsername, ip)
 		if err != nil {
-			log.Printf("Failed to connect to LDAP server: %v", err)
+			log.Printf("Error checking login attempts: %v", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
-		defer conn.Close()
-
-		err = conn.Bind(dn, pass)
-		if err != nil {
-			log.Printf("Server DN Bind Failed: %v", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		if block != nil {
+			sendLoginBlocked(w, decodedUsername, block)
 			return
 		}
 
-		var ou string
-		var responseSent bool
+		// Continue with directory authentication using decodedUsername and decodedPassword...
+		identity, err := Directory.Authenticate(r.Context(), decodedUsername, decodedPassword)
+		if errors.Is(err, directory.ErrInvalidCredentials) || errors.Is(err, directory.ErrAmbiguousUser) {
+			// One answer for every refusal, so usernames cannot be probed
+			log.Printf("LDAP Entries Mismatch: %v", err)
 
-		performSearch := func(searchBase, userType string) {
-			req := ldap.NewSearchRequest(
-				searchBase,
-				ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
-				ldapUserFilter,
-				nil,
-				nil,
-			)
+			attempt.failed()
+			if attempt.Block != nil {
+				sendLoginBlocked(w, decodedUsername, attempt.Block)
+				return
+			}
+
+			resp := AuthResponse{
+				Valid:    false,
+				Username: decodedUsername,
+			}
 
-			sr, err := conn.Search(req)
+			jsonResponse, err := json.Marshal(resp)
 			if err != nil {
-				log.Printf("Search Failed: %v", err)
+				http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
 				return
 			}
-
-			for _, entry := range sr.Entries {
-				dn := entry.DN
-				bindCredentials := decodedPassword
-
-				if dn != "" && bindCredentials != "" {
-					err = conn.Bind(dn, bindCredentials)
-					if err != nil {
-						log.Printf("%s Bind Failed: %v", userType, err)
-					} else {
-						log.Printf("%s Bind Successful", userType)
-						ou = userType
-
-						if !responseSent {
-							responseSent = true
-
-							userId := generateUserId()
-							employeeId, mobileNumber, err := getEmployeeInfo(decodedUsername)
-
-							if err != nil {
-								log.Printf("Error retrieving employee info: %v", err)
-								http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-								return
-							}
-
-							err = insertSessionData(userId, decodedUsername, ou, employeeId)
-							if err != nil {
-								log.Printf("Error inserting session data: %v", err)
-								http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-								return
-							}
-
-							// Generate JWT
-							tokenString, err := generateJWT(userId, decodedUsername, employeeId)
-							if err != nil {
-								log.Printf("Error generating JWT: %v", err)
-								http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-								return
-							}
-
-							resp := AuthResponse{
-								Valid:        true,
-								UserId:       userId,
-								Username:     decodedUsername,
-								EmployeeId:   employeeId,   // ✅ now included
-								MobileNumber: mobileNumber, // ✅ added
-								Token:        tokenString,  // ✅ JWT token added
-							}
-
-							jsonResponse, err := json.Marshal(resp)
-							if err != nil {
-								http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
-								return
-							}
-							encrypted, err := utils.Encrypt(jsonResponse)
-							if err != nil {
-								http.Error(w, "Encryption failed", http.StatusInternalServerError)
-								return
-							}
-							w.Header().Set("Content-Type", "application/json")
-							_ = json.NewEncoder(w).Encode(map[string]string{
-								"Data": encrypted,
-							})
-
-						}
-					}
-				} else {
-					log.Println("DN or password is null or undefined")
-				}
+			encrypted, err := utils.Encrypt(jsonResponse)
+			if err != nil {
+				http.Error(w, "Encryption failed", http.StatusInternalServerError)
+				return
 			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"Data": encrypted,
+			})
+			return
+		}
+		// Anything but a credential failure gives the attempt back
+		if releaseErr := ldapBackend.releaseAttempt(attempt); releaseErr != nil {
+			log.Printf("Error releasing login attempt: %v", releaseErr)
+		}
+		if errors.Is(err, directory.ErrUnavailable) {
+			log.Printf("Directory unavailable: %v", err)
+			http.Error(w, "Directory unavailable, try again later", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			log.Printf("Directory authentication failed: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
 		}
 
-		// Check staff first
-		performSearch(searchBaseStaff, "staff")
-		// If not staff, check faculty
-		performSearch(searchBaseFaculty, "faculty")
-		// If not faculty, check project
-		performSearch(searchbase_project, "project")
-		// If not faculty, check student
-		//		performSearch(searchBaseStudent, "student")  //comment for later use
-
-		if ou == "" {
-			log.Println("LDAP Entries Mismatch")
-			if !responseSent {
-				responseSent = true
-				resp := AuthResponse{
-					Valid:    false,
-					Username: decodedUsername,
-				}
-
-				jsonResponse, err := json.Marshal(resp)
-				if err != nil {
-					http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
-					return
-				}
-				encrypted, err := utils.Encrypt(jsonResponse)
-				if err != nil {
-					http.Error(w, "Encryption failed", http.StatusInternalServerError)
-					return
-				}
-				w.Header().Set("Content-Type", "application/json")
-				_ = json.NewEncoder(w).Encode(map[string]string{
-					"Data": encrypted,
-				})
-
-			} else {
-				// If LDAP authentication succeeds for any user type,
-				// reset the responseSent flag to false
-				responseSent = false
-			}
+		if err := ldapBackend.clearFailures(decodedUsername); err != nil {
+			log.Printf("Error clearing login failures: %v", err)
 		}
 
-	}))
-	loggedHandler.ServeHTTP(w, r)
-}
+		// Keep the directory mapped roles in step with LDAP
+		ldapBackend.provisionRoles(identity)
 
-// generateUserId creates and returns a new UUID string.
-func generateUserId() string {
-	return uuid.New().String()
-}
+		employeeId, mobileNumber, err := ldapBackend.employeeInfo(decodedUsername)
 
-// Add this new function to handle existing active sessions
-func updatePreviousActiveSessions(employeeId string) error {
-	// Connection string for postgres Server
-	connectionString := credentials.Getdatabasemeivan()
+		if err != nil {
+			log.Printf("Error retrieving employee info: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
 
-	db, err := sql.Open("postgres", connectionString)
-	if err != nil {
-		return fmt.Errorf("DB open error: %v", err)
-	}
-	defer db.Close()
+		// Refusing session policies answer now rather than after the OTP
+		if err := ldapBackend.checkSessionLimit(decodedUsername, employeeId); err != nil {
+			if errors.Is(err, errSessionLimit) {
+				sendSessionLimit(w, decodedUsername)
+				return
+			}
+			log.Printf("Error checking session limit: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
 
-	// Update all active sessions for this employee_id
-	query := `UPDATE Session_Data 
-			  SET Is_Active = '0', 
-				  idletimeout = '1', 
-				  Logout_Date = NOW() 
-			  WHERE Employee_id = $1 AND Is_Active = '1'`
+		// The session is only created once the second factor passes
+		txn, preAuthToken, err := ldapBackend.startTransaction(decodedUsername, employeeId, string(identity.UserType))
+		if err != nil {
+			log.Printf("Error starting login transaction: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
 
-	result, err := db.Exec(query, employeeId)
-	if err != nil {
-		return fmt.Errorf("failed to update previous sessions: %v", err)
-	}
+		// Second factor the client should ask for
+		preferredFactor, err := ldapBackend.preferredFactor(employeeId)
+		if err != nil {
+			log.Printf("Error reading preferred factor: %v", err)
+		}
 
-	// Log how many rows were affected (optional)
-	rowsAffected, err := result.RowsAffected()
-	if err == nil {
-		log.Printf("Updated %d previous active sessions for employee %s", rowsAffected, employeeId)
-	}
+		resp := AuthResponse{
+			Valid:        true,
+			UserId:       txn.ID,
+			Username:     decodedUsername,
+			EmployeeId:   employeeId,
+			MobileNumber: otp.MaskMobile(mobileNumber), // masked until the OTP is verified
+			PreAuthToken: preAuthToken,
+			ExpiresIn:    int64(preAuthTokenTTL.Seconds()),
 
-	return nil
+			PreferredFactor: preferredFactor,
+		}
+
+		jsonResponse, err := json.Marshal(resp)
+		if err != nil {
+			http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+			return
+		}
+		encrypted, err := utils.Encrypt(jsonResponse)
+		if err != nil {
+			http.Error(w, "Encryption failed", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"Data": encrypted,
+		})
+	}))
+	loggedHandler.ServeHTTP(w, r)
 }
 
-// Updated insertSessionData function
-func insertSessionData(userId, username, ou, employeeId string) error {
-	// First, update any existing active sessions for this employee
-	err := updatePreviousActiveSessions(employeeId)
-	if err != nil {
-		log.Printf("Warning: Failed to update previous sessions: %v", err)
-		// You can decide whether to continue or return error here
-		// For now, we'll continue with the login process
+// provisionDirectoryRoles grants and revokes the roles that roleRules map
+// from the identity. Failures are logged; they do not block the login.
+func provisionDirectoryRoles(identity *directory.Identity) {
+	if len(roleRules) == 0 {
+		return
 	}
-
-	// Connection string for postgres Server
-	connectionString := credentials.Getdatabasemeivan()
-
-	db, err := sql.Open("postgres", connectionString)
+	roles := directory.MapRoles(identity, roleRules)
+	changed, err := databasecommon.ProvisionRoles(identity.Username, roles, roleProvisionSource)
 	if err != nil {
-		return fmt.Errorf("DB open error: %v", err)
+		log.Printf("Role provisioning failed for %s: %v", identity.Username, err)
+		return
 	}
-	defer db.Close()
-
-	// Insert new session record
-	query := `INSERT INTO Session_Data 
-		(Session_Id, Logout_Date, Username, Is_Active, idletimeout, Department, User_id, Employee_id, Login_Date) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())`
-
-	_, err = db.Exec(query, userId, nil, username, "1", "0", ou, userId, employeeId)
-	if err != nil {
-		return err
+	if changed {
+		auth.InvalidateRoles(identity.Username)
 	}
+}
 
-	log.Printf("New session created for employee %s with session ID %s", employeeId, userId)
-	return nil
+// generateUserId creates and returns a new UUID string.
+func generateUserId() string {
+	return uuid.New().String()
 }
 
 // getEmployeeInfo queries employeebasicinfo table to retrieve EmployeeId and MobileNumber.
 func getEmployeeInfo(username string) (string, string, error) {
-	// Connection string for postgres Server
-	connectionString := credentials.Getdatabasehr()
-
-	db, err := sql.Open("postgres", connectionString)
-	if err != nil {
-		return "", "", err
-	}
-	defer db.Close()
+	db := hrDB
 
 	// ✅ Fetch both EmployeeId and Mobilenumber
 	query := `SELECT EmployeeId, Mobilenumber FROM employeebasicinfo WHERE LoginName = $1`
 	row := db.QueryRow(query, username)
 
 	var employeeId, mobileNumber string
-	err = row.Scan(&employeeId, &mobileNumber)
+	err := row.Scan(&employeeId, &mobileNumber)
 	if err != nil {
 		return "", "", err
 	}
@@ -0,0 +1,216 @@
+package controllerslogin
+
+import (
+	"Hrmodule/config"
+	"Hrmodule/directory"
+	"Hrmodule/utils"
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeLoginBackend is a loginBackend that records the calls made to it.
+type fakeLoginBackend struct {
+	refuseKey bool        // authorize answers 401
+	blocked   *loginBlock // reserveAttempt refuses with this block
+	failBlock *loginBlock // block that stands if the attempt fails
+
+	reserved, released, cleared, started int
+}
+
+func (f *fakeLoginBackend) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if f.refuseKey {
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (f *fakeLoginBackend) reserveAttempt(username, ip string) (*loginAttempt, *loginBlock, error) {
+	if f.blocked != nil {
+		return nil, f.blocked, nil
+	}
+	f.reserved++
+	return &loginAttempt{Block: f.failBlock}, nil, nil
+}
+
+func (f *fakeLoginBackend) releaseAttempt(a *loginAttempt) error {
+	f.released++
+	return nil
+}
+
+func (f *fakeLoginBackend) clearFailures(username string) error {
+	f.cleared++
+	return nil
+}
+
+func (f *fakeLoginBackend) provisionRoles(identity *directory.Identity) {}
+
+func (f *fakeLoginBackend) employeeInfo(username string) (string, string, error) {
+	return "E100", "9876543210", nil
+}
+
+func (f *fakeLoginBackend) checkSessionLimit(username, employeeId string) error {
+	return nil
+}
+
+func (f *fakeLoginBackend) startTransaction(username, employeeId, ou string) (*loginTransaction, string, error) {
+	f.started++
+	return &loginTransaction{ID: "txn-1", Username: username, EmployeeID: employeeId, Department: ou, State: loginLdapPassed}, "preauth-token", nil
+}
+
+func (f *fakeLoginBackend) preferredFactor(employeeId string) (string, error) {
+	return "sms", nil
+}
+
+// encryptCredential encrypts s the way the client does: AES-ECB with
+// PKCS5 padding, hex encoded.
+func encryptCredential(t *testing.T, key, s string) string {
+	t.Helper()
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pad := aes.BlockSize - len(s)%aes.BlockSize
+	data := append([]byte(s), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	out := make([]byte, len(data))
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Encrypt(out[i:i+aes.BlockSize], data[i:i+aes.BlockSize])
+	}
+	return hex.EncodeToString(out)
+}
+
+func TestHandleLDAPAuth(t *testing.T) {
+	const key = "0123456789abcdef0123456789abcdef"
+	if err := utils.Configure(&config.Config{Security: config.Security{EncryptionKey: key}}); err != nil {
+		t.Fatal(err)
+	}
+	savedKey, savedDir, savedBackend := encryptionKey, Directory, ldapBackend
+	t.Cleanup(func() { encryptionKey, Directory, ldapBackend = savedKey, savedDir, savedBackend })
+	encryptionKey = key
+	Directory = directory.NewMemoryDirectory(directory.MemoryEntry{
+		Identity: directory.Identity{Username: "alice", UserType: directory.UserTypeStaff},
+		Password: "correct horse",
+	})
+
+	tests := []struct {
+		name       string
+		backend    fakeLoginBackend
+		username   string
+		password   string
+		plain      bool // send the credentials unencrypted
+		wantStatus int
+		wantValid  bool
+		wantCalls  [4]int // reserved, released, cleared, started
+	}{
+		{
+			name:       "valid credentials start a login transaction",
+			username:   "alice",
+			password:   "correct horse",
+			wantStatus: http.StatusOK,
+			wantValid:  true,
+			wantCalls:  [4]int{1, 1, 1, 1},
+		},
+		{
+			name:       "wrong password is counted and refused",
+			username:   "alice",
+			password:   "battery staple",
+			wantStatus: http.StatusOK,
+			wantCalls:  [4]int{1, 0, 0, 0},
+		},
+		{
+			name:       "unknown user gets the same answer",
+			username:   "mallory",
+			password:   "correct horse",
+			wantStatus: http.StatusOK,
+			wantCalls:  [4]int{1, 0, 0, 0},
+		},
+		{
+			name:       "failure that reaches the backoff answers 429",
+			backend:    fakeLoginBackend{failBlock: &loginBlock{RetryAfter: 2 * time.Second}},
+			username:   "alice",
+			password:   "battery staple",
+			wantStatus: http.StatusTooManyRequests,
+			wantCalls:  [4]int{1, 0, 0, 0},
+		},
+		{
+			name:       "blocked login never reaches the directory",
+			backend:    fakeLoginBackend{blocked: &loginBlock{Locked: true, RetryAfter: time.Minute}},
+			username:   "alice",
+			password:   "correct horse",
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "refused API key stops the request",
+			backend:    fakeLoginBackend{refuseKey: true},
+			username:   "alice",
+			password:   "correct horse",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unencrypted credentials are rejected",
+			username:   "alice",
+			password:   "correct horse",
+			plain:      true,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := tt.backend
+			ldapBackend = &backend
+
+			username, password := tt.username, tt.password
+			if !tt.plain {
+				username = encryptCredential(t, key, username)
+				password = encryptCredential(t, key, password)
+			}
+			body, _ := json.Marshal(AuthRequest{Token: "abc123", Username: username, Password: password})
+			req := httptest.NewRequest(http.MethodPost, "/HRldap", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			HandleLDAPAuth(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			calls := [4]int{backend.reserved, backend.released, backend.cleared, backend.started}
+			if calls != tt.wantCalls {
+				t.Errorf("reserved, released, cleared, started = %v, want %v", calls, tt.wantCalls)
+			}
+			if rec.Code == http.StatusUnauthorized {
+				return
+			}
+
+			var envelope map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			plain, err := utils.Decrypt(envelope["Data"])
+			if err != nil {
+				t.Fatalf("decrypt response: %v", err)
+			}
+			var resp AuthResponse
+			if err := json.Unmarshal(plain, &resp); err != nil {
+				t.Fatalf("decoded response is not JSON: %v", err)
+			}
+			if resp.Valid != tt.wantValid {
+				t.Errorf("valid = %v, want %v", resp.Valid, tt.wantValid)
+			}
+			if tt.wantValid {
+				if resp.PreAuthToken != "preauth-token" || resp.EmployeeId != "E100" {
+					t.Errorf("response = %+v, want the transaction's pre-auth token and employee", resp)
+				}
+				if resp.MobileNumber != "******3210" {
+					t.Errorf("mobile = %q, want it masked", resp.MobileNumber)
+				}
+			}
+		})
+	}
+}
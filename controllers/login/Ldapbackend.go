@@ -0,0 +1,80 @@
+// Package controllerslogin provides the backend /HRldap works against
+// around the directory bind.
+//
+// It ensures:
+//   - The API key check and every database call of HandleLDAPAuth sit behind loginBackend
+//   - Production uses dbLoginBackend; tests swap ldapBackend as they swap Directory
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerslogin
+
+import (
+	"Hrmodule/auth"
+	"Hrmodule/directory"
+	"net/http"
+)
+
+// loginBackend is what HandleLDAPAuth needs besides the Directory.
+type loginBackend interface {
+	// authorize checks the API key and client address and answers the
+	// request itself when they are refused.
+	authorize(w http.ResponseWriter, r *http.Request) bool
+	reserveAttempt(username, ip string) (*loginAttempt, *loginBlock, error)
+	releaseAttempt(a *loginAttempt) error
+	clearFailures(username string) error
+	provisionRoles(identity *directory.Identity)
+	employeeInfo(username string) (employeeId, mobileNumber string, err error)
+	checkSessionLimit(username, employeeId string) error
+	startTransaction(username, employeeId, ou string) (*loginTransaction, string, error)
+	preferredFactor(employeeId string) (string, error)
+}
+
+// ldapBackend is the loginBackend of /HRldap.
+var ldapBackend loginBackend = dbLoginBackend{}
+
+// dbLoginBackend is the loginBackend on the injected pools.
+type dbLoginBackend struct{}
+
+func (dbLoginBackend) authorize(w http.ResponseWriter, r *http.Request) bool {
+	return auth.HandleRequestfor_apiname_ipaddress_token(w, r)
+}
+
+func (dbLoginBackend) reserveAttempt(username, ip string) (*loginAttempt, *loginBlock, error) {
+	return reserveLoginAttempt(username, ip)
+}
+
+func (dbLoginBackend) releaseAttempt(a *loginAttempt) error {
+	return a.release()
+}
+
+func (dbLoginBackend) clearFailures(username string) error {
+	return clearLoginFailures(username)
+}
+
+func (dbLoginBackend) provisionRoles(identity *directory.Identity) {
+	provisionDirectoryRoles(identity)
+}
+
+func (dbLoginBackend) employeeInfo(username string) (string, string, error) {
+	return getEmployeeInfo(username)
+}
+
+func (dbLoginBackend) checkSessionLimit(username, employeeId string) error {
+	return checkSessionLimit(username, employeeId)
+}
+
+func (dbLoginBackend) startTransaction(username, employeeId, ou string) (*loginTransaction, string, error) {
+	return startLoginTransaction(username, employeeId, ou)
+}
+
+func (dbLoginBackend) preferredFactor(employeeId string) (string, error) {
+	return getPreferredFactor(employeeId)
+}
@@ -4,9 +4,11 @@
 //
 // It ensures:
 //   - Secure request validation using token-based authentication
-//   - Insertion of OTP details (username, mobile number, session ID, etc.)
-//   - Automatic expiry of OTPs after 45 seconds
-//   - Encrypted response payloads for added security
+//   - Only the pre-auth token of an open login transaction can request a code
+//   - Server-side OTP generation; only a salted hash is stored in otp_details
+//   - Delivery to the mobile number on record through the configured otp.Sender
+//   - Expiry, resend gap, resend count and lockout from otp.CurrentPolicy
+//   - Encrypted response payloads that never contain the code
 //
 // --- Creator's Info ---
 //
@@ -16,27 +18,30 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 26-08-2025
+// Last Modified Date: 17-10-2026
 package controllerslogin
 
 import (
 	"Hrmodule/auth"
-	credentials "Hrmodule/dbconfig"
-	"Hrmodule/utils"
+	"Hrmodule/otp"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
 	_ "github.com/lib/pq"
 )
 
-// OTPDetails maps to otp_details table (without id, since it's auto-increment)
+// OTPDetails maps to otp_details table (without id, since it's auto-increment).
+// The code itself is generated by the server, so there is no otp field, and
+// the mobile number is taken from employeebasicinfo rather than from the client.
 type OTPDetails struct {
 	Username      string    `json:"username"`
 	MobileNo      int64     `json:"mobileno"`
-	OTP           int       `json:"otp"`
 	OTPSendOn     time.Time `json:"otpsendon"`
 	OTPVerifiedOn time.Time `json:"otpverifiedon"`
 	Status        int       `json:"status"`
@@ -46,8 +51,129 @@ type OTPDetails struct {
 	Token         string    `json:"token"`
 }
 
-// InsertOTPHandler inserts a new OTPDetails row
+// issueOTP generates a code for username, stores its hash in otp_details and
+// hands it to otp.Sender. The resend column records how many codes the
+// session had before this one. It returns the otp_details id and the
+// destination mobile number, or a *otp.Rejection if the policy refuses.
+func issueOTP(ctx context.Context, username, sessionId string) (int, string, error) {
+	policy := otp.CurrentPolicy
+
+	_, mobileNo, err := getEmployeeInfo(username)
+	if err != nil {
+		return 0, "", fmt.Errorf("employee lookup error: %v", err)
+	}
+
+	db := meivanDB
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("begin error: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Serialise issue requests of the same session so limits hold under concurrency
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, "otp:"+sessionId); err != nil {
+		return 0, "", fmt.Errorf("lock error: %v", err)
+	}
+
+	if rej, err := checkOTPLockout(tx, username, mobileNo); err != nil || rej != nil {
+		return 0, "", firstError(rej, err)
+	}
+
+	var sent int
+	var sinceLast sql.NullFloat64
+	err = tx.QueryRow(`
+		SELECT COUNT(*), EXTRACT(EPOCH FROM NOW() - MAX(otpsendon))
+		FROM otp_details
+		WHERE username = $1 AND session_id = $2`, username, sessionId).Scan(&sent, &sinceLast)
+	if err != nil {
+		return 0, "", fmt.Errorf("resend lookup error: %v", err)
+	}
+
+	if rej := policy.CheckResend(sent, time.Duration(sinceLast.Float64*float64(time.Second))); rej != nil {
+		return 0, "", rej
+	}
+
+	code, err := otp.Generate(policy.Length)
+	if err != nil {
+		return 0, "", fmt.Errorf("generate error: %v", err)
+	}
+	hash, err := otp.Hash(code)
+	if err != nil {
+		return 0, "", fmt.Errorf("hash error: %v", err)
+	}
+
+	// Insert query
+	query := `
+		INSERT INTO otp_details 
+		(username, mobileno, otp_hash, otpsendon, status, otpvalidtill, session_id, resend, attempts)
+		VALUES ($1, $2, $3, NOW(), 0, NOW() + $4 * interval '1 second', $5, $6, 0)
+		RETURNING id;
+	`
+
+	var id int
+	err = tx.QueryRow(query, username, mobileNo, hash, int(policy.Validity.Seconds()), sessionId, sent).Scan(&id)
+	if err != nil {
+		return 0, "", fmt.Errorf("insert error: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, "", fmt.Errorf("commit error: %v", err)
+	}
+
+	receipt, sendErr := otp.Sender.Send(ctx, otp.Message{
+		OTPID:    id,
+		Username: username,
+		MobileNo: mobileNo,
+		Code:     code,
+		ValidFor: policy.Validity,
+	})
+	if err := recordOTPDelivery(db, id, receipt, sendErr); err != nil {
+		log.Printf("Warning: failed to record OTP delivery for %d: %v", id, err)
+	}
+	if sendErr != nil {
+		return 0, "", fmt.Errorf("delivery error: %v", sendErr)
+	}
+
+	return id, mobileNo, nil
+}
+
+// recordOTPDelivery stores the delivery outcome on the otp_details row.
+func recordOTPDelivery(db *sql.DB, id int, receipt otp.Receipt, sendErr error) error {
+	status, ref := "sent", receipt.ProviderRef
+	if sendErr != nil {
+		status = "failed"
+		if ref == "" {
+			ref = sendErr.Error()
+		}
+	}
+
+	query := `
+		UPDATE otp_details
+		SET delivery_status = $2, delivery_attempts = $3, delivery_ref = $4,
+		    delivered_on = CASE WHEN $5 THEN NOW() END
+		WHERE id = $1`
+
+	_, err := db.Exec(query, id, status, receipt.Attempts, ref, sendErr == nil)
+	return err
+}
+
+// firstError returns rej as an error if set, otherwise err.
+func firstError(rej *otp.Rejection, err error) error {
+	if rej != nil {
+		return rej
+	}
+	return err
+}
+
+// InsertOTPHandler generates an OTP, stores its hash and sends it to the user
 func InsertOTPHandler(w http.ResponseWriter, r *http.Request) {
+	serveOTPIssue(w, r, "OTP sent successfully")
+}
+
+// serveOTPIssue is the body of /Loginotp and /Loginresend: it issues a code
+// for the login transaction of the pre-auth token and answers with message.
+func serveOTPIssue(w http.ResponseWriter, r *http.Request, message string) {
 	// Step 1: Parse request body
 	var req OTPDetails
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -72,59 +198,36 @@ func InsertOTPHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// DB connection
-		connectionString := credentials.Getdatabasemeivan()
-		db, err := sql.Open("postgres", connectionString)
+		// Username and session come from the pre-auth token, not the body
+		txn, err := loginTransactionFromRequest(r, loginLdapPassed, loginOTPSent)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("DB open error: %v", err), http.StatusInternalServerError)
+			sendLoginTransactionError(w, err)
 			return
 		}
-		defer db.Close()
-
-		// Insert query
-		query := `
-			INSERT INTO otp_details 
-			(username, mobileno, otp, otpsendon, status, otpvalidtill, session_id, resend)
-			VALUES ($1, $2, $3, NOW(), 0, NOW() + interval '45 seconds', $4, 0)
-			RETURNING id;
-		`
-
-		var id int
-		err = db.QueryRow(query,
-			req.Username,
-			req.MobileNo,
-			req.OTP,
-			req.SessionID,
-		).Scan(&id)
 
-		if err != nil {
-			http.Error(w, "Error inserting: "+err.Error(), http.StatusInternalServerError)
+		id, mobileNo, err := issueOTP(r.Context(), txn.Username, txn.ID)
+		var rej *otp.Rejection
+		if errors.As(err, &rej) {
+			sendOTPRejection(w, rej)
 			return
 		}
-
-		// Success response
-		resp := map[string]interface{}{
-			"message":    "OTP record inserted successfully",
-			"id":         id,
-			"session_id": req.SessionID,
-		}
-
-		// Encrypt response
-		jsonResponse, err := json.MarshalIndent(resp, "", "    ")
 		if err != nil {
-			http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+			log.Printf("Error issuing OTP: %v", err)
+			http.Error(w, "Unable to send OTP", http.StatusInternalServerError)
 			return
 		}
 
-		encrypted, err := utils.Encrypt(jsonResponse)
-		if err != nil {
-			http.Error(w, "Encryption failed", http.StatusInternalServerError)
+		if err := advanceLoginTransaction(meivanDB, txn, loginOTPSent, loginLdapPassed, loginOTPSent); err != nil {
+			sendLoginTransactionError(w, err)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"Data": encrypted,
+		// Success response (the code is never echoed back)
+		sendEncryptedResponse(w, map[string]interface{}{
+			"message":    message,
+			"id":         id,
+			"session_id": txn.ID,
+			"mobileno":   otp.MaskMobile(mobileNo),
 		})
 	}))
 
@@ -0,0 +1,381 @@
+// Package controllerslogin provides brute-force protection for /HRldap.
+//
+// It ensures:
+//   - Failed logins are counted per username and per client IP in login_attempts
+//   - Each attempt is charged before the bind, so concurrent guesses cannot pass one check together
+//   - After LOGIN_BACKOFF_AFTER failures each retry waits twice as long as the last
+//   - Reaching the lockout threshold blocks the subject for LOGIN_LOCKOUT_DURATION
+//   - A blocked request never reaches the directory, so directory lockouts are not triggered
+//   - An admin can lift a block through /LoginUnlock
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerslogin
+
+import (
+	"Hrmodule/auth"
+	"Hrmodule/config"
+	"Hrmodule/utils"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// Subjects counted in login_attempts.
+const (
+	subjectUsername = "username"
+	subjectIP       = "ip"
+)
+
+// loginThrottlePolicy holds the limits applied to failed /HRldap logins.
+type loginThrottlePolicy struct {
+	BackoffAfter         int           // failures allowed before backoff starts
+	BackoffBase          time.Duration // first backoff delay, doubled per further failure
+	BackoffMax           time.Duration // longest backoff delay
+	UserLockoutThreshold int           // failures per username before lockout
+	IPLockoutThreshold   int           // failures per client IP before lockout
+	LockoutDuration      time.Duration // how long a lockout lasts
+	FailureWindow        time.Duration // failures older than this are forgotten
+}
+
+var loginThrottle = loginThrottlePolicy{
+	BackoffAfter:         3,
+	BackoffBase:          2 * time.Second,
+	BackoffMax:           5 * time.Minute,
+	UserLockoutThreshold: 10,
+	IPLockoutThreshold:   50,
+	LockoutDuration:      30 * time.Minute,
+	FailureWindow:        time.Hour,
+}
+
+// configureLoginThrottle applies the LOGIN_* settings.
+func configureLoginThrottle(cfg *config.Config) {
+	c := cfg.LoginThrottle
+	loginThrottle = loginThrottlePolicy{
+		BackoffAfter:         c.BackoffAfter,
+		BackoffBase:          c.BackoffBase,
+		BackoffMax:           c.BackoffMax,
+		UserLockoutThreshold: c.UserLockoutThreshold,
+		IPLockoutThreshold:   c.IPLockoutThreshold,
+		LockoutDuration:      c.LockoutDuration,
+		FailureWindow:        c.FailureWindow,
+	}
+}
+
+// delay returns how long a subject with the given failures must wait.
+func (p loginThrottlePolicy) delay(failures, lockoutThreshold int) time.Duration {
+	if failures >= lockoutThreshold {
+		return p.LockoutDuration
+	}
+	if failures < p.BackoffAfter {
+		return 0
+	}
+	d := float64(p.BackoffBase) * math.Pow(2, float64(failures-p.BackoffAfter))
+	if d > float64(p.BackoffMax) {
+		return p.BackoffMax
+	}
+	return time.Duration(d)
+}
+
+// loginBlock describes why a login is refused before it reaches the directory.
+type loginBlock struct {
+	Locked     bool          // lockout threshold reached, not just backoff
+	RetryAfter time.Duration // time until the next attempt is accepted
+}
+
+// RetryAfterSeconds returns RetryAfter rounded up to whole seconds.
+func (b *loginBlock) RetryAfterSeconds() int {
+	return int(math.Ceil(b.RetryAfter.Seconds()))
+}
+
+// clientIP returns the address of the caller without the port.
+func clientIP(r *http.Request) string {
+	return auth.ClientIP(r)
+}
+
+// loginAttempt is a login charged against the throttle before the
+// directory bind. It is counted as a failure up front, so concurrent
+// requests cannot all pass the check before any failure is recorded.
+type loginAttempt struct {
+	subjects []chargedSubject
+	Block    *loginBlock // block that stands if the attempt fails, nil if none
+}
+
+// chargedSubject is one login_attempts row charged by a loginAttempt.
+type chargedSubject struct {
+	kind, value  string
+	prevFailures int  // failures before the charge
+	failures     int  // failures including the charge
+	blocked      bool // the charge set blocked_until
+	locked       bool // the charge reached the lockout threshold
+}
+
+// reserveLoginAttempt charges a login against the username and the client
+// IP in one transaction. If either is blocked nothing is charged and the
+// longest active block is returned instead.
+func reserveLoginAttempt(username, ip string) (*loginAttempt, *loginBlock, error) {
+	db := meivanDB
+
+	ensure := `
+		INSERT INTO login_attempts (subject_type, subject, failures, last_failure)
+		VALUES ($1, $2, 0, NOW())
+		ON CONFLICT (subject_type, subject) DO NOTHING`
+
+	// The row lock serialises concurrent attempts on the same subject
+	lock := `
+		SELECT failures, last_failure < NOW() - $3 * interval '1 second',
+			COALESCE(EXTRACT(EPOCH FROM blocked_until - NOW()), 0), locked
+		FROM login_attempts
+		WHERE subject_type = $1 AND subject = $2
+		FOR UPDATE`
+
+	// A lockout restarts the count at BackoffAfter, so after it ends
+	// further failures back off again before the next lockout
+	charge := `
+		UPDATE login_attempts
+		SET failures = CASE WHEN $4 THEN $5 ELSE $3 END, last_failure = NOW(),
+			blocked_until = CASE WHEN $6::float8 > 0 THEN NOW() + $6::float8 * interval '1 second' ELSE blocked_until END,
+			locked = CASE WHEN $6::float8 > 0 THEN $4 ELSE locked END
+		WHERE subject_type = $1 AND subject = $2`
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("login attempts transaction error: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Usernames are always locked before addresses, so two attempts
+	// cannot wait on each other
+	var active *loginBlock
+	attempt := &loginAttempt{}
+	window := loginThrottle.FailureWindow.Seconds()
+	for _, subject := range []struct {
+		kind, value string
+		threshold   int
+	}{
+		{subjectUsername, strings.ToLower(username), loginThrottle.UserLockoutThreshold},
+		{subjectIP, ip, loginThrottle.IPLockoutThreshold},
+	} {
+		if subject.value == "" {
+			continue
+		}
+		if _, err := tx.Exec(ensure, subject.kind, subject.value); err != nil {
+			return nil, nil, fmt.Errorf("login attempts insert error: %v", err)
+		}
+		var failures int
+		var stale, locked bool
+		var remaining float64
+		if err := tx.QueryRow(lock, subject.kind, subject.value, window).Scan(&failures, &stale, &remaining, &locked); err != nil {
+			return nil, nil, fmt.Errorf("login attempts lookup error: %v", err)
+		}
+		if remaining > 0 {
+			retry := time.Duration(remaining * float64(time.Second))
+			if active == nil || retry > active.RetryAfter {
+				active = &loginBlock{Locked: locked, RetryAfter: retry}
+			}
+			continue
+		}
+		if stale {
+			failures = 0
+		}
+		attempt.subjects = append(attempt.subjects, chargedSubject{
+			kind:         subject.kind,
+			value:        subject.value,
+			prevFailures: failures,
+			failures:     failures + 1,
+			locked:       failures+1 >= subject.threshold,
+		})
+		c := &attempt.subjects[len(attempt.subjects)-1]
+
+		delay := loginThrottle.delay(c.failures, subject.threshold)
+		c.blocked = delay > 0
+		if c.blocked && (attempt.Block == nil || delay > attempt.Block.RetryAfter) {
+			attempt.Block = &loginBlock{Locked: c.locked, RetryAfter: delay}
+		}
+		if active != nil {
+			continue
+		}
+		if _, err := tx.Exec(charge, subject.kind, subject.value, c.failures, c.locked, loginThrottle.BackoffAfter, delay.Seconds()); err != nil {
+			return nil, nil, fmt.Errorf("login attempts charge error: %v", err)
+		}
+	}
+	if active != nil {
+		return nil, active, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("login attempts commit error: %v", err)
+	}
+	return attempt, nil, nil
+}
+
+// failed logs the lockouts a failed attempt caused. The failure itself was
+// already counted by reserveLoginAttempt.
+func (a *loginAttempt) failed() {
+	for _, c := range a.subjects {
+		if c.locked {
+			log.Printf("Login locked for %s %s after %d failures", c.kind, c.value, c.failures)
+		}
+	}
+}
+
+// release refunds an attempt that did not end in a credential failure.
+// A block set by the charge is lifted with it while it is still the
+// current one; otherwise only the count is taken back.
+func (a *loginAttempt) release() error {
+	db := meivanDB
+
+	restore := `
+		UPDATE login_attempts
+		SET failures = $3, blocked_until = NULL, locked = FALSE
+		WHERE subject_type = $1 AND subject = $2 AND blocked_until > NOW()`
+
+	refund := `
+		UPDATE login_attempts
+		SET failures = GREATEST(failures - 1, 0)
+		WHERE subject_type = $1 AND subject = $2`
+
+	for _, c := range a.subjects {
+		if c.blocked {
+			res, err := db.Exec(restore, c.kind, c.value, c.prevFailures)
+			if err != nil {
+				return fmt.Errorf("release login attempt error: %v", err)
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				continue
+			}
+		}
+		if _, err := db.Exec(refund, c.kind, c.value); err != nil {
+			return fmt.Errorf("release login attempt error: %v", err)
+		}
+	}
+	return nil
+}
+
+// clearLoginFailures resets the username counter after a successful login.
+// The IP counter is kept, so one valid account cannot reset an attack from
+// the same address.
+func clearLoginFailures(username string) error {
+	db := meivanDB
+
+	query := `DELETE FROM login_attempts WHERE subject_type = $1 AND subject = $2`
+	if _, err := db.Exec(query, subjectUsername, strings.ToLower(username)); err != nil {
+		return fmt.Errorf("clear login failures error: %v", err)
+	}
+	return nil
+}
+
+// sendLoginBlocked answers a throttled /HRldap request with 429 and the
+// encrypted AuthResponsefalse carrying the retry-after value.
+func sendLoginBlocked(w http.ResponseWriter, username string, block *loginBlock) {
+	msg := "Too many failed attempts, try again later"
+	if block.Locked {
+		msg = "Account temporarily locked after repeated failed logins"
+	}
+	resp := AuthResponsefalse{
+		Valid:      false,
+		Username:   username,
+		Error:      msg,
+		Locked:     block.Locked,
+		RetryAfter: block.RetryAfterSeconds(),
+	}
+
+	jsonResponse, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+	encrypted, err := utils.Encrypt(jsonResponse)
+	if err != nil {
+		http.Error(w, "Encryption failed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(block.RetryAfterSeconds()))
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"Data": encrypted,
+	})
+}
+
+// LoginUnlockRequest represents the request body of /LoginUnlock
+type LoginUnlockRequest struct {
+	Token    string `json:"token"`
+	Username string `json:"username"` // username to unlock, optional
+	IP       string `json:"ip"`       // client IP to unlock, optional
+}
+
+// LoginUnlockHandler handles POST /LoginUnlock. It clears the failure
+// counters and blocks of a username and/or client IP. The route requires
+// auth.PermLoginUnlock.
+func LoginUnlockHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed, use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Unable to read request body", http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	var req LoginUnlockRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "Invalid JSON input", http.StatusBadRequest)
+		return
+	}
+
+	// If token provided in body, inject into header
+	if req.Token != "" {
+		r.Header.Set("token", req.Token)
+	}
+	if !auth.HandleRequestfor_apiname_ipaddress_token(w, r) {
+		return
+	}
+
+	loggedHandler := auth.LogRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if req.Username == "" && req.IP == "" {
+			http.Error(w, "username or ip is required", http.StatusBadRequest)
+			return
+		}
+
+		db := meivanDB
+
+		query := `
+			DELETE FROM login_attempts
+			WHERE (subject_type = $1 AND subject = $2) OR (subject_type = $3 AND subject = $4)`
+
+		res, err := db.Exec(query, subjectUsername, strings.ToLower(req.Username), subjectIP, req.IP)
+		if err != nil {
+			log.Printf("Error unlocking login: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		cleared, _ := res.RowsAffected()
+
+		log.Printf("Login unlocked by %s: username=%q ip=%q", auth.UsernameFromContext(r.Context()), req.Username, req.IP)
+		sendEncryptedResponse(w, map[string]interface{}{
+			"success": true,
+			"message": "Login unlocked",
+			"cleared": cleared,
+		})
+	}))
+	loggedHandler.ServeHTTP(w, r)
+}
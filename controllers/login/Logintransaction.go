@@ -0,0 +1,218 @@
+// Package controllerslogin provides the two-phase login state machine.
+//
+// A login moves through login_transaction as
+// ldap_passed → otp_sent → otp_verified → session_active:
+//   - /HRldap creates the transaction and returns a short-lived pre-auth token
+//   - /Loginotp and /Loginotpresend accept only that token and send the OTP
+//   - /Loginotpupdate or /TotpVerify checks the second factor, creates the
+//     session_data row and exchanges the pre-auth token for the session JWT
+//
+// Every transition is a conditional update, so a step cannot be skipped or
+// replayed, and the transaction expires together with its pre-auth token.
+// otp_verified is committed with the code that passed, and session_active
+// with the session and its refresh token. If creating the session fails the
+// login stays otp_verified, and repeating the /Loginotpupdate or /TotpVerify
+// request completes it without a new code.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerslogin
+
+import (
+	"Hrmodule/auth"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// States of a login transaction.
+const (
+	loginLdapPassed    = "ldap_passed"
+	loginOTPSent       = "otp_sent"
+	loginOTPVerified   = "otp_verified"
+	loginSessionActive = "session_active"
+)
+
+// preAuthTokenTTL is the lifetime of the pre-auth token and of its login
+// transaction; the second factor must be completed within it.
+var preAuthTokenTTL = 5 * time.Minute
+
+// errLoginTransaction is returned when the transaction is unknown, expired
+// or not in a state that allows the requested step.
+var errLoginTransaction = errors.New("login transaction expired or invalid")
+
+// loginTransaction is a row of login_transaction. Its ID becomes the
+// Session_Id once the login completes.
+type loginTransaction struct {
+	ID         string
+	Username   string
+	EmployeeID string
+	Department string
+	State      string
+}
+
+// startLoginTransaction records a successful LDAP bind and returns the
+// signed pre-auth token for the new transaction.
+func startLoginTransaction(username, employeeId, ou string) (*loginTransaction, string, error) {
+	db := meivanDB
+
+	txn := &loginTransaction{
+		ID:         generateUserId(),
+		Username:   username,
+		EmployeeID: employeeId,
+		Department: ou,
+		State:      loginLdapPassed,
+	}
+
+	query := `
+		INSERT INTO login_transaction (id, username, employee_id, department, state, created_on, updated_on, expires_on)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW(), NOW() + make_interval(secs => $6))`
+
+	_, err := db.Exec(query, txn.ID, username, employeeId, ou, txn.State, preAuthTokenTTL.Seconds())
+	if err != nil {
+		return nil, "", fmt.Errorf("insert login transaction error: %v", err)
+	}
+
+	token, err := auth.SignClaims(auth.NewPreAuthClaims(username, employeeId, txn.ID, preAuthTokenTTL))
+	if err != nil {
+		return nil, "", err
+	}
+	return txn, token, nil
+}
+
+// loginTransactionFromRequest loads the transaction named by the pre-auth
+// token in the request context. It returns errLoginTransaction unless the
+// transaction is unexpired and in one of the given states.
+func loginTransactionFromRequest(r *http.Request, states ...string) (*loginTransaction, error) {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok || claims.Scope != auth.ScopePreAuth {
+		return nil, errLoginTransaction
+	}
+
+	db := meivanDB
+
+	query := `
+		SELECT id, username, employee_id, department, state
+		FROM login_transaction
+		WHERE id = $1 AND username = $2 AND expires_on > NOW()`
+
+	txn := &loginTransaction{}
+	err := db.QueryRow(query, claims.Session, claims.Username).
+		Scan(&txn.ID, &txn.Username, &txn.EmployeeID, &txn.Department, &txn.State)
+	if err == sql.ErrNoRows {
+		return nil, errLoginTransaction
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup login transaction error: %v", err)
+	}
+
+	for _, state := range states {
+		if txn.State == state {
+			return txn, nil
+		}
+	}
+	return nil, errLoginTransaction
+}
+
+// advanceLoginTransaction moves the transaction to state `to` if it is still
+// unexpired and in one of the `from` states. Concurrent or replayed steps
+// lose the race and get errLoginTransaction. q is the pool, or the database
+// transaction the step must commit with.
+func advanceLoginTransaction(q sqlRunner, txn *loginTransaction, to string, from ...string) error {
+	query := `
+		UPDATE login_transaction
+		SET state = $2, updated_on = NOW()
+		WHERE id = $1 AND state = ANY($3) AND expires_on > NOW()`
+
+	res, err := q.Exec(query, txn.ID, to, pq.Array(from))
+	if err != nil {
+		return fmt.Errorf("update login transaction error: %v", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update login transaction error: %v", err)
+	}
+	if n == 0 {
+		return errLoginTransaction
+	}
+	txn.State = to
+	return nil
+}
+
+// sendLoginTransactionError answers a request whose login transaction is not
+// usable, or logs and hides an internal error.
+func sendLoginTransactionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errLoginTransaction) {
+		sendEncryptedStatus(w, http.StatusUnauthorized, map[string]interface{}{
+			"success":    false,
+			"validcheck": "0",
+			"reason":     "LOGIN_EXPIRED",
+			"message":    "Login expired or already completed, sign in again",
+		})
+		return
+	}
+	log.Printf("Login transaction error: %v", err)
+	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+}
+
+// completeLogin is the last step of a login in state otp_verified: it signs
+// the session JWT, then creates the session_data row and the refresh token
+// and marks the transaction session_active together (see createSession).
+// If that fails the transaction stays otp_verified and can be completed by
+// repeating the request.
+func completeLogin(w http.ResponseWriter, r *http.Request, txn *loginTransaction) {
+	// The transaction id becomes both userId and Session_Id
+	tokenString, err := generateJWT(txn.ID, txn.Username, txn.EmployeeID, txn.ID)
+	if err != nil {
+		log.Printf("Error generating JWT: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// The device completing the second factor is the one the session belongs to
+	device := deviceFromRequest(r)
+	refreshToken, err := createSession(txn, device)
+	if err != nil {
+		if errors.Is(err, errLoginTransaction) {
+			sendLoginTransactionError(w, err)
+			return
+		}
+		if errors.Is(err, errSessionLimit) {
+			sendEncryptedStatus(w, http.StatusConflict, map[string]interface{}{
+				"success":    false,
+				"validcheck": "0",
+				"reason":     "SESSION_LIMIT",
+				"message":    "Maximum concurrent sessions reached, log out of another session first",
+			})
+			return
+		}
+		log.Printf("Error creating session: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	sendEncryptedResponse(w, map[string]interface{}{
+		"success":       true,
+		"message":       "OTP verified successfully",
+		"validcheck":    "1",
+		"username":      txn.Username,
+		"userId":        txn.ID,
+		"session_id":    txn.ID,
+		"EmployeeId":    txn.EmployeeID,
+		"token":         tokenString,
+		"refresh_token": refreshToken,
+		"expires_in":    int64(accessTokenTTL.Seconds()),
+	})
+}
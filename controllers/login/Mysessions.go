@@ -0,0 +1,227 @@
+// Package controllerslogin provides the "My sessions" APIs.
+//
+// It ensures:
+//   - Each session records the user agent, client IP and a device label at login
+//   - /MySessions lists the caller's active and recent sessions
+//   - /MySessionRevoke ends one of the caller's sessions through the same
+//     path as /SessionTimeout, so that session's JWT stops working at once
+//
+// The device label is taken from the X-Device-Label header of the request
+// completing the login, or derived from its user agent.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+//
+// Path: My Sessions
+package controllerslogin
+
+import (
+	"Hrmodule/auth"
+	databaselogin "Hrmodule/database/login"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// mySessionsLimit is the number of sessions /MySessions returns.
+const mySessionsLimit = 20
+
+// Column sizes of session_data.user_agent and device_label.
+const (
+	maxUserAgentLength   = 512
+	maxDeviceLabelLength = 64
+)
+
+// sessionDevice describes the client a session was opened from.
+type sessionDevice struct {
+	UserAgent string
+	IP        string
+	Label     string
+}
+
+// deviceFromRequest returns the device of the request completing a login.
+func deviceFromRequest(r *http.Request) sessionDevice {
+	ua := truncate(r.UserAgent(), maxUserAgentLength)
+	label := truncate(strings.Map(func(c rune) rune {
+		if unicode.IsControl(c) {
+			return -1
+		}
+		return c
+	}, strings.TrimSpace(r.Header.Get("X-Device-Label"))), maxDeviceLabelLength)
+	if label == "" {
+		label = deviceLabel(ua)
+	}
+	return sessionDevice{UserAgent: ua, IP: clientIP(r), Label: label}
+}
+
+// deviceLabel derives a short "Browser on OS" label from a user agent.
+func deviceLabel(ua string) string {
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(ua, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "okhttp"), strings.Contains(ua, "Dart/"):
+		browser = "Mobile app"
+	}
+
+	os := "unknown device"
+	switch {
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		os = "iOS"
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	}
+	return browser + " on " + os
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// MySessionsRequest represents the request body of /MySessions and /MySessionRevoke
+type MySessionsRequest struct {
+	Token     string `json:"token"`
+	SessionID string `json:"session_id"` // session to revoke (/MySessionRevoke only)
+}
+
+// readMySessionsRequest reads the body, injects the token and runs the
+// API validation shared by the My Sessions handlers.
+func readMySessionsRequest(w http.ResponseWriter, r *http.Request) (*MySessionsRequest, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed, use POST", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Unable to read request body", http.StatusBadRequest)
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	var req MySessionsRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "Invalid JSON input", http.StatusBadRequest)
+		return nil, false
+	}
+
+	// If token provided in body, inject into header
+	if req.Token != "" {
+		r.Header.Set("token", req.Token)
+	}
+	if !auth.HandleRequestfor_apiname_ipaddress_token(w, r) {
+		return nil, false
+	}
+	return &req, true
+}
+
+// MySessionsHandler handles POST /MySessions. It lists the active and the
+// most recent closed sessions of the calling employee.
+func MySessionsHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := readMySessionsRequest(w, r); !ok {
+		return
+	}
+
+	loggedHandler := auth.LogRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessions, err := databaselogin.EmployeeSessions(auth.EmployeeIdFromContext(r.Context()), mySessionsLimit)
+		if err != nil {
+			log.Printf("Error listing sessions: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		current := auth.SessionFromContext(r.Context())
+		for i := range sessions {
+			sessions[i].Current = sessions[i].SessionID != nil && *sessions[i].SessionID == current
+		}
+
+		sendEncryptedResponse(w, map[string]interface{}{
+			"Status":  200,
+			"message": "Success",
+			"Data": map[string]interface{}{
+				"No Of Records": len(sessions),
+				"Records":       sessions,
+			},
+		})
+	}))
+	loggedHandler.ServeHTTP(w, r)
+}
+
+// MySessionRevokeHandler handles POST /MySessionRevoke. It ends one of the
+// caller's own sessions, including the current one.
+func MySessionRevokeHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := readMySessionsRequest(w, r)
+	if !ok {
+		return
+	}
+
+	loggedHandler := auth.LogRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if req.SessionID == "" {
+			http.Error(w, "Missing required field: session_id", http.StatusBadRequest)
+			return
+		}
+
+		owner, active, err := databaselogin.SessionOwner(req.SessionID)
+		// Another employee's session is reported like a missing one
+		if errors.Is(err, databaselogin.ErrSessionNotFound) ||
+			(err == nil && owner != auth.EmployeeIdFromContext(r.Context())) {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("Error reading session %s: %v", req.SessionID, err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if active {
+			if err := closeSession(req.SessionID, 0, logoutReasonRevoked); err != nil {
+				log.Printf("Error revoking session %s: %v", req.SessionID, err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		sendEncryptedResponse(w, map[string]interface{}{
+			"success":    true,
+			"message":    "Session revoked",
+			"session_id": req.SessionID,
+			"current":    req.SessionID == auth.SessionFromContext(r.Context()),
+		})
+	}))
+	loggedHandler.ServeHTTP(w, r)
+}
@@ -0,0 +1,295 @@
+// Package controllerslogin provides the concurrent-session policy applied
+// when a login creates its session_data row.
+//
+// It ensures:
+//   - Each role allows at most MaxSessions active sessions per employee
+//   - At the limit the oldest session is evicted (logout_reason 'superseded')
+//     or, for refusing policies, the new login is rejected
+//   - Users holding several roles get the most permissive of their policies
+//   - Evicted sessions are invalidated immediately, like an explicit logout
+//
+// Policies come from SESSION_DEFAULT_POLICY and SESSION_ROLE_POLICIES, e.g.
+//
+//	SESSION_DEFAULT_POLICY=3:evict
+//	SESSION_ROLE_POLICIES=HR Admin=1:refuse;Faculty=5:evict
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerslogin
+
+import (
+	"Hrmodule/auth"
+	"Hrmodule/config"
+	"Hrmodule/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Values of session_data.logout_reason.
+const (
+	logoutReasonLogout     = "logout"       // ended by the user
+	logoutReasonIdle       = "idle_timeout" // ended by the client's idle timer
+	logoutReasonSuperseded = "superseded"   // evicted by a newer login
+	logoutReasonTokenReuse = "token_reuse"  // refresh token replayed
+	logoutReasonRevoked    = "revoked"      // ended from /MySessionRevoke
+)
+
+// Actions of a sessionPolicy at its limit.
+const (
+	sessionPolicyEvict  = "evict"
+	sessionPolicyRefuse = "refuse"
+)
+
+// sessionPolicy limits the active sessions of one employee.
+type sessionPolicy struct {
+	MaxSessions int  // active sessions allowed, at least 1
+	Evict       bool // at the limit evict the oldest, otherwise refuse the login
+}
+
+// defaultSessionPolicy applies to users none of whose roles has a policy.
+// It matches the previous behaviour: a new login replaces the old session.
+var defaultSessionPolicy = sessionPolicy{MaxSessions: 1, Evict: true}
+
+// roleSessionPolicies holds the policy per lower-cased role name.
+var roleSessionPolicies = map[string]sessionPolicy{}
+
+// errSessionLimit is returned when a refusing policy is at its limit.
+var errSessionLimit = errors.New("maximum concurrent sessions reached")
+
+// configureSessionPolicies applies SESSION_DEFAULT_POLICY and
+// SESSION_ROLE_POLICIES.
+func configureSessionPolicies(cfg *config.Config) config.Errors {
+	var errs config.Errors
+	p, err := parseSessionPolicy(cfg.Sessions.DefaultPolicy)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("SESSION_DEFAULT_POLICY: %v", err))
+	} else {
+		defaultSessionPolicy = p
+	}
+
+	policies := map[string]sessionPolicy{}
+	for _, part := range strings.Split(cfg.Sessions.RolePolicies, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		role, value, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(role) == "" {
+			errs = append(errs, fmt.Errorf("SESSION_ROLE_POLICIES: %s: want Role=max:action", part))
+			continue
+		}
+		p, err := parseSessionPolicy(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SESSION_ROLE_POLICIES: %s: %v", part, err))
+			continue
+		}
+		policies[strings.ToLower(strings.TrimSpace(role))] = p
+	}
+	roleSessionPolicies = policies
+	return errs
+}
+
+// parseSessionPolicy parses "max:evict" or "max:refuse".
+func parseSessionPolicy(v string) (sessionPolicy, error) {
+	max, action, ok := strings.Cut(strings.TrimSpace(v), ":")
+	if !ok {
+		return sessionPolicy{}, fmt.Errorf("%q: want max:evict or max:refuse", v)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(max))
+	if err != nil || n <= 0 {
+		return sessionPolicy{}, fmt.Errorf("%q: max must be a positive integer", v)
+	}
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case sessionPolicyEvict:
+		return sessionPolicy{MaxSessions: n, Evict: true}, nil
+	case sessionPolicyRefuse:
+		return sessionPolicy{MaxSessions: n, Evict: false}, nil
+	}
+	return sessionPolicy{}, fmt.Errorf("%q: action must be evict or refuse", v)
+}
+
+// sessionPolicyFor returns the policy of username: the one allowing the most
+// sessions among its roles, evict winning a tie. If the roles cannot be read
+// the default policy is used.
+func sessionPolicyFor(username string) sessionPolicy {
+	roles, err := auth.RolesOf(username)
+	if err != nil {
+		log.Printf("Error reading roles of %s, using default session policy: %v", username, err)
+		return defaultSessionPolicy
+	}
+
+	var best *sessionPolicy
+	for _, role := range roles {
+		p, ok := roleSessionPolicies[strings.ToLower(role)]
+		if !ok {
+			continue
+		}
+		if best == nil || p.MaxSessions > best.MaxSessions ||
+			(p.MaxSessions == best.MaxSessions && p.Evict && !best.Evict) {
+			best = &p
+		}
+	}
+	if best == nil {
+		return defaultSessionPolicy
+	}
+	return *best
+}
+
+// checkSessionLimit returns errSessionLimit if a new login of username would
+// be refused, so /HRldap can answer before the second factor is sent.
+// createSession checks again when the session is actually created.
+func checkSessionLimit(username, employeeId string) error {
+	policy := sessionPolicyFor(username)
+	if policy.Evict {
+		return nil
+	}
+
+	db := meivanDB
+
+	var active int
+	query := `SELECT COUNT(*) FROM Session_Data WHERE Employee_id = $1 AND Is_Active = '1'`
+	if err := db.QueryRow(query, employeeId).Scan(&active); err != nil {
+		return fmt.Errorf("count sessions error: %v", err)
+	}
+	if active >= policy.MaxSessions {
+		return errSessionLimit
+	}
+	return nil
+}
+
+// createSession completes the login transaction txn: in one database
+// transaction it moves txn from otp_verified to session_active, inserts the
+// session_data row under the session policy of the user and starts its
+// refresh token family, whose token it returns. Logins of the same employee
+// are serialised with an advisory lock, so two parallel logins cannot both
+// take the last slot. It returns errSessionLimit if the policy refuses the
+// login and errLoginTransaction if txn is not otp_verified or has expired.
+// On any error nothing is written: the second factor stays used and txn
+// stays otp_verified, so the client completes by repeating its request.
+func createSession(txn *loginTransaction, device sessionDevice) (string, error) {
+	sessionId, username, ou, employeeId := txn.ID, txn.Username, txn.Department, txn.EmployeeID
+	policy := sessionPolicyFor(username)
+
+	db := meivanDB
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("begin error: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Claiming the transaction row also stops a concurrent completion of it
+	if err := advanceLoginTransaction(tx, txn, loginSessionActive, loginOTPVerified); err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('session_data:' || $1))`, employeeId); err != nil {
+		return "", fmt.Errorf("session lock error: %v", err)
+	}
+
+	rows, err := tx.Query(`SELECT Session_Id FROM Session_Data
+		WHERE Employee_id = $1 AND Is_Active = '1'
+		ORDER BY Login_Date, id`, employeeId)
+	if err != nil {
+		return "", fmt.Errorf("query active sessions error: %v", err)
+	}
+	var active []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("scan active session error: %v", err)
+		}
+		active = append(active, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("read active sessions error: %v", err)
+	}
+
+	// Oldest sessions that must go to make room for this one
+	var evicted []string
+	if over := len(active) - policy.MaxSessions + 1; over > 0 {
+		if !policy.Evict {
+			return "", errSessionLimit
+		}
+		evicted = active[:over]
+		_, err := tx.Exec(`UPDATE Session_Data
+			SET Is_Active = '0', Logout_Date = NOW(), logout_reason = $2
+			WHERE Session_Id = ANY($1) AND Is_Active = '1'`, pq.Array(evicted), logoutReasonSuperseded)
+		if err != nil {
+			return "", fmt.Errorf("evict sessions error: %v", err)
+		}
+		// Their refresh tokens must not bring them back
+		_, err = tx.Exec(`UPDATE refresh_token SET revoked_on = NOW()
+			WHERE session_id = ANY($1) AND revoked_on IS NULL`, pq.Array(evicted))
+		if err != nil {
+			return "", fmt.Errorf("revoke evicted refresh tokens error: %v", err)
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO Session_Data
+		(Session_Id, Logout_Date, Username, Is_Active, idletimeout, Department, User_id, Employee_id, Login_Date,
+		 user_agent, client_ip, device_label)
+		VALUES ($1, NULL, $2, '1', '0', $3, $1, $4, NOW(), $5, $6, $7)`,
+		sessionId, username, ou, employeeId, device.UserAgent, device.IP, device.Label)
+	if err != nil {
+		return "", fmt.Errorf("insert session error: %v", err)
+	}
+
+	refreshToken, err := issueRefreshToken(tx, sessionId)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("commit error: %v", err)
+	}
+
+	// Tokens of the evicted sessions stop working immediately
+	auth.InvalidateSession(evicted...)
+	if len(evicted) > 0 {
+		log.Printf("Evicted %d session(s) of employee %s for new session %s", len(evicted), employeeId, sessionId)
+	}
+	log.Printf("New session created for employee %s with session ID %s", employeeId, sessionId)
+	return refreshToken, nil
+}
+
+// sendSessionLimit answers a login refused by the session policy with 409
+// and the encrypted AuthResponsefalse.
+func sendSessionLimit(w http.ResponseWriter, username string) {
+	resp := AuthResponsefalse{
+		Valid:    false,
+		Username: username,
+		Error:    "Maximum concurrent sessions reached, log out of another session first",
+	}
+
+	jsonResponse, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+	encrypted, err := utils.Encrypt(jsonResponse)
+	if err != nil {
+		http.Error(w, "Encryption failed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"Data": encrypted,
+	})
+}
@@ -0,0 +1,183 @@
+// Package controllerslogin provides server-side idle timeout.
+//
+// It ensures:
+//   - A session idle for longer than its window is closed even if the
+//     browser never calls /SessionTimeout
+//   - The window slides: every request (see auth.TouchSession) restarts it
+//   - Each role can have its own window; a user gets the shortest window of
+//     the roles that have one, so sensitive roles keep their stricter limit
+//   - Reaped sessions get idletimeout = 1, Logout_Date and logout_reason
+//     'idle_timeout', and their JWTs stop working at once
+//
+// Windows come from SESSION_IDLE_TIMEOUT and SESSION_ROLE_IDLE_TIMEOUTS, e.g.
+//
+//	SESSION_IDLE_TIMEOUT=30m
+//	SESSION_ROLE_IDLE_TIMEOUTS=HR Admin=15m;Faculty=2h
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerslogin
+
+import (
+	"Hrmodule/auth"
+	"Hrmodule/config"
+	databaselogin "Hrmodule/database/login"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// defaultIdleTimeout applies to users none of whose roles has a window.
+var defaultIdleTimeout = 30 * time.Minute
+
+// roleIdleTimeouts holds the idle window per lower-cased role name.
+var roleIdleTimeouts = map[string]time.Duration{}
+
+// sessionReaperInterval is how often the reaper looks for idle sessions.
+var sessionReaperInterval = time.Minute
+
+// configureSessionReaper applies SESSION_IDLE_TIMEOUT,
+// SESSION_REAPER_INTERVAL and SESSION_ROLE_IDLE_TIMEOUTS.
+func configureSessionReaper(cfg *config.Config) config.Errors {
+	var errs config.Errors
+	defaultIdleTimeout = cfg.Sessions.IdleTimeout
+	sessionReaperInterval = cfg.Sessions.ReaperInterval
+
+	timeouts := map[string]time.Duration{}
+	for _, part := range strings.Split(cfg.Sessions.RoleIdleTimeouts, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		role, value, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(role) == "" {
+			errs = append(errs, fmt.Errorf("SESSION_ROLE_IDLE_TIMEOUTS: %s: want Role=duration", part))
+			continue
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("SESSION_ROLE_IDLE_TIMEOUTS: %s: must be a positive duration", part))
+			continue
+		}
+		timeouts[strings.ToLower(strings.TrimSpace(role))] = d
+	}
+	roleIdleTimeouts = timeouts
+	return errs
+}
+
+// idleTimeoutFor returns the idle window of username: the shortest window
+// among its roles, or the default if none of them has one. If the roles
+// cannot be read the default is used.
+func idleTimeoutFor(username string) time.Duration {
+	roles, err := auth.RolesOf(username)
+	if err != nil {
+		log.Printf("Error reading roles of %s, using default idle timeout: %v", username, err)
+		return defaultIdleTimeout
+	}
+
+	window := time.Duration(0)
+	for _, role := range roles {
+		if d, ok := roleIdleTimeouts[strings.ToLower(role)]; ok && (window == 0 || d < window) {
+			window = d
+		}
+	}
+	if window == 0 {
+		return defaultIdleTimeout
+	}
+	return window
+}
+
+// shortestIdleTimeout is the smallest window any user can have; sessions
+// idle for less are never candidates.
+func shortestIdleTimeout() time.Duration {
+	shortest := defaultIdleTimeout
+	for _, d := range roleIdleTimeouts {
+		if d < shortest {
+			shortest = d
+		}
+	}
+	return shortest
+}
+
+// reapIdleSessions closes the sessions idle for longer than their window
+// and returns how many were closed.
+func reapIdleSessions() (int, error) {
+	// Activity buffered on this instance must count before judging idleness
+	if err := auth.FlushSessionActivity(); err != nil {
+		return 0, fmt.Errorf("flush activity: %v", err)
+	}
+
+	candidates, err := databaselogin.IdleSessions(shortestIdleTimeout())
+	if err != nil {
+		return 0, err
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	db := meivanDB
+
+	// Re-checked in SQL so activity flushed by another instance meanwhile wins
+	query := `
+		UPDATE session_data
+		SET Is_Active = 0, idletimeout = 1, Logout_Date = NOW(), logout_reason = $3
+		WHERE Session_Id = $1 AND Is_Active = 1
+		  AND COALESCE(last_activity, login_date) < NOW() - $2 * interval '1 second'`
+
+	windows := make(map[string]time.Duration)
+	var reaped []string
+	for _, s := range candidates {
+		window, ok := windows[s.Username]
+		if !ok {
+			window = idleTimeoutFor(s.Username)
+			windows[s.Username] = window
+		}
+		if s.Idle < window {
+			continue
+		}
+
+		res, err := db.Exec(query, s.SessionId, window.Seconds(), logoutReasonIdle)
+		if err != nil {
+			return len(reaped), fmt.Errorf("close idle session %s: %v", s.SessionId, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			reaped = append(reaped, s.SessionId)
+		}
+	}
+
+	// Tokens of the reaped sessions stop working immediately
+	auth.InvalidateSession(reaped...)
+	return len(reaped), nil
+}
+
+// StartSessionReaper closes idle sessions every SESSION_REAPER_INTERVAL
+// until ctx is done. Running it on several instances is safe: each close is
+// a conditional update.
+func StartSessionReaper(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(sessionReaperInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n, err := reapIdleSessions()
+				if err != nil {
+					log.Printf("Session reaper: %v", err)
+				}
+				if n > 0 {
+					log.Printf("Session reaper closed %d idle session(s)", n)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
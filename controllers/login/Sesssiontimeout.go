@@ -11,15 +11,13 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 09-07-2025
+// Last Modified Date: 17-10-2026
 package controllerslogin
 
 import (
 	"Hrmodule/auth"
-	credentials "Hrmodule/dbconfig"
 	"Hrmodule/utils"
 	"bytes"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -41,28 +39,35 @@ type APIResponse struct {
 	Message string `json:"message"` // Human-readable message
 }
 
-// UpdateSessionLogout updates the Is_Active flag to 0, sets idletimeout, and sets the Logout_Date to NOW()
+// UpdateSessionLogout updates the Is_Active flag to 0, sets idletimeout, and sets the Logout_Date to NOW().
+// logout_reason records whether the user or the idle timer ended the session.
 func UpdateSessionLogout(sessionId string, idleTimeout int) error {
-	// Connection string for Postgres
-	connectionString := credentials.Getdatabasemeivan()
-
-	db, err := sql.Open("postgres", connectionString)
-	if err != nil {
-		return fmt.Errorf("DB open error: %v", err)
+	reason := logoutReasonLogout
+	if idleTimeout == 1 {
+		reason = logoutReasonIdle
 	}
-	defer db.Close()
+	return closeSession(sessionId, idleTimeout, reason)
+}
+
+// closeSession marks a session inactive with the given logout_reason and
+// invalidates its JWT. Every way of ending a session goes through here.
+func closeSession(sessionId string, idleTimeout int, reason string) error {
+	db := meivanDB
 
 	// ✅ Fixed Postgres syntax: proper placeholders and comma placement
 	query := `
 		UPDATE session_data 
-		SET Is_Active = 0, idletimeout = $2, Logout_Date = NOW() 
+		SET Is_Active = 0, idletimeout = $2, Logout_Date = NOW(), logout_reason = $3
 		WHERE Session_Id = $1`
 
-	_, err = db.Exec(query, sessionId, idleTimeout)
+	_, err := db.Exec(query, sessionId, idleTimeout, reason)
 	if err != nil {
 		return fmt.Errorf("update error: %v", err)
 	}
 
+	// Reject the session's JWT from now on
+	auth.InvalidateSession(sessionId)
+
 	return nil
 }
 
@@ -115,6 +120,12 @@ func SessionTimeoutHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Only HR admins may close a session other than their own
+		if req.SessionID != auth.SessionFromContext(r.Context()) && !auth.Can(r.Context(), auth.PermSessionReadAny) {
+			auth.RespondForbidden(w, "Not allowed to close another session")
+			return
+		}
+
 		// Validate idletimeout value (should be 0 or 1, default to 0 if not provided)
 		if req.IdleTimeout != 0 && req.IdleTimeout != 1 {
 			req.IdleTimeout = 0 // Default to 0 if invalid value provided
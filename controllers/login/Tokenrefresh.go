@@ -0,0 +1,254 @@
+// Package controllerslogin provides the refresh token flow that keeps a
+// logged-in user signed in without repeating LDAP and OTP.
+//
+// It ensures:
+//   - Refresh tokens are opaque random values, stored only as SHA-256 hashes
+//   - Every refresh token is single use and is rotated on each call
+//   - Presenting an already used token revokes the whole session family
+//   - The family has an absolute lifetime that rotation does not extend
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerslogin
+
+import (
+	"Hrmodule/auth"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	_ "github.com/lib/pq"
+)
+
+// TokenRefreshRequest represents the request body for /TokenRefresh
+type TokenRefreshRequest struct {
+	Token        string `json:"token"`         // API token, validated like every other endpoint
+	RefreshToken string `json:"refresh_token"` // Refresh token issued at login completion or by a previous refresh
+}
+
+var (
+	errRefreshInvalid = errors.New("refresh token not recognised")
+	errRefreshExpired = errors.New("refresh token expired or session closed")
+	errRefreshReused  = errors.New("refresh token reuse detected, session revoked")
+)
+
+// refreshSession holds the session identity a refresh token belongs to
+type refreshSession struct {
+	SessionID  string
+	UserID     string
+	Username   string
+	EmployeeID string
+}
+
+// newRefreshToken returns a random opaque token and its SHA-256 hash
+func newRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashRefreshToken(token), nil
+}
+
+// hashRefreshToken returns the hex encoded SHA-256 of a refresh token
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueRefreshToken starts a new refresh token family for the given session.
+func issueRefreshToken(q sqlRunner, sessionId string) (string, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	// The expiry comes from the database clock, the same one rotateRefreshToken
+	// compares it with, so a host time zone never shifts it
+	query := `INSERT INTO refresh_token (token_hash, session_id, issued_on, expires_on)
+		VALUES ($1, $2, NOW(), NOW() + make_interval(secs => $3))`
+
+	_, err = q.Exec(query, hash, sessionId, refreshTokenTTL.Seconds())
+	if err != nil {
+		return "", fmt.Errorf("insert refresh token error: %v", err)
+	}
+
+	return token, nil
+}
+
+// rotateRefreshToken consumes a refresh token and returns its replacement.
+// A token that was already consumed revokes every token of the session and
+// closes the session itself, since either the client or an attacker holds a
+// stolen copy.
+func rotateRefreshToken(presented string) (*refreshSession, string, error) {
+	db := meivanDB
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, "", fmt.Errorf("begin error: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT r.id, r.session_id, r.expires_on > NOW(), r.used_on IS NOT NULL OR r.revoked_on IS NOT NULL,
+		       s.is_active, s.user_id, s.username, s.employee_id
+		FROM refresh_token r
+		JOIN session_data s ON s.session_id = r.session_id
+		WHERE r.token_hash = $1
+		FOR UPDATE OF r`
+
+	var id int64
+	var unexpired, consumed bool
+	var isActive int
+	var session refreshSession
+	err = tx.QueryRow(query, hashRefreshToken(presented)).Scan(
+		&id, &session.SessionID, &unexpired, &consumed,
+		&isActive, &session.UserID, &session.Username, &session.EmployeeID,
+	)
+	if err == sql.ErrNoRows {
+		return nil, "", errRefreshInvalid
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("lookup refresh token error: %v", err)
+	}
+
+	if consumed {
+		if err := revokeRefreshFamily(tx, session.SessionID); err != nil {
+			return nil, "", err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, "", fmt.Errorf("commit error: %v", err)
+		}
+		auth.InvalidateSession(session.SessionID)
+		log.Printf("Refresh token reuse detected, revoked session %s", session.SessionID)
+		return nil, "", errRefreshReused
+	}
+
+	if isActive != 1 || !unexpired {
+		return nil, "", errRefreshExpired
+	}
+
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err := tx.Exec(`UPDATE refresh_token SET used_on = NOW() WHERE id = $1`, id); err != nil {
+		return nil, "", fmt.Errorf("consume refresh token error: %v", err)
+	}
+
+	// The replacement inherits the family expiry so rotation never extends it
+	_, err = tx.Exec(`INSERT INTO refresh_token (token_hash, session_id, issued_on, expires_on)
+		SELECT $1, session_id, NOW(), expires_on FROM refresh_token WHERE id = $2`, hash, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("insert refresh token error: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, "", fmt.Errorf("commit error: %v", err)
+	}
+
+	return &session, token, nil
+}
+
+// revokeRefreshFamily revokes all refresh tokens of a session and marks the session inactive
+func revokeRefreshFamily(tx *sql.Tx, sessionId string) error {
+	_, err := tx.Exec(`UPDATE refresh_token SET revoked_on = NOW()
+		WHERE session_id = $1 AND revoked_on IS NULL`, sessionId)
+	if err != nil {
+		return fmt.Errorf("revoke refresh tokens error: %v", err)
+	}
+
+	_, err = tx.Exec(`UPDATE session_data SET Is_Active = 0, Logout_Date = NOW(), logout_reason = $2
+		WHERE Session_Id = $1 AND Is_Active = 1`, sessionId, logoutReasonTokenReuse)
+	if err != nil {
+		return fmt.Errorf("revoke session error: %v", err)
+	}
+	return nil
+}
+
+// TokenRefreshHandler handles POST requests to the /TokenRefresh endpoint.
+// It exchanges a refresh token for a new access token and a new refresh token.
+func TokenRefreshHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed. Use POST.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Read body (so we can inject token if provided in JSON)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Unable to read request body", http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body)) // restore for downstream
+
+	var req TokenRefreshRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "Invalid JSON input", http.StatusBadRequest)
+		return
+	}
+
+	// If token provided in body, inject into header
+	if req.Token != "" {
+		r.Header.Set("token", req.Token)
+	}
+
+	// Authenticate (token/IP validation)
+	if !auth.HandleRequestfor_apiname_ipaddress_token(w, r) {
+		return
+	}
+
+	loggedHandler := auth.LogRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if req.RefreshToken == "" {
+			http.Error(w, "Missing required field: refresh_token", http.StatusBadRequest)
+			return
+		}
+
+		session, refreshToken, err := rotateRefreshToken(req.RefreshToken)
+		if err != nil {
+			if errors.Is(err, errRefreshInvalid) || errors.Is(err, errRefreshExpired) || errors.Is(err, errRefreshReused) {
+				sendEncryptedStatus(w, http.StatusUnauthorized, map[string]interface{}{
+					"valid": false,
+					"error": err.Error(),
+				})
+				return
+			}
+			log.Printf("Error rotating refresh token: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		tokenString, err := generateJWT(session.UserID, session.Username, session.EmployeeID, session.SessionID)
+		if err != nil {
+			log.Printf("Error generating JWT: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		sendEncryptedResponse(w, map[string]interface{}{
+			"valid":         true,
+			"token":         tokenString,
+			"refresh_token": refreshToken,
+			"expires_in":    int64(accessTokenTTL.Seconds()),
+		})
+	}))
+
+	loggedHandler.ServeHTTP(w, r)
+}
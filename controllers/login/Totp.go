@@ -0,0 +1,461 @@
+// Package controllerslogin provides TOTP (authenticator app) enrolment and
+// verification as an alternative second factor to SMS OTP.
+//
+// It ensures:
+//   - The TOTP secret is stored AES-GCM encrypted per EmployeeId
+//   - Enrolment only takes effect after the user confirms a code
+//   - A confirmed enrolment is only replaced on a valid code from it, so a
+//     session token alone cannot swap the second factor
+//   - The confirmed secret stays in use until its replacement is confirmed
+//   - A code cannot be replayed within its validity window
+//   - Wrong codes count towards the same lockout as SMS OTP
+//   - Each employee can choose SMS or TOTP as the preferred factor
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerslogin
+
+import (
+	"Hrmodule/auth"
+	"Hrmodule/otp"
+	"Hrmodule/utils"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	_ "github.com/lib/pq"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Second factors an employee can prefer.
+const (
+	FactorSMS  = "sms"
+	FactorTOTP = "totp"
+)
+
+// totpIssuer is the account issuer shown in authenticator apps.
+var totpIssuer = "IITM HR"
+
+// TotpRequest represents the request body of the TOTP endpoints
+type TotpRequest struct {
+	Token           string   `json:"token"`
+	OTP             otp.Code `json:"otp"`              // code from the authenticator app; on /TotpEnrol, from the enrolled app
+	PreferredFactor string   `json:"preferred_factor"` // /MfaPreference only: "sms" or "totp"
+}
+
+// readTotpRequest reads the body, injects the API token and validates the caller.
+// It returns false if a response has already been written.
+func readTotpRequest(w http.ResponseWriter, r *http.Request, req *TotpRequest) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed, use POST", http.StatusMethodNotAllowed)
+		return false
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Unable to read request body", http.StatusBadRequest)
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	if err := json.Unmarshal(body, req); err != nil {
+		http.Error(w, "Invalid JSON input", http.StatusBadRequest)
+		return false
+	}
+
+	// If token provided in body, inject into header
+	if req.Token != "" {
+		r.Header.Set("token", req.Token)
+	}
+
+	return auth.HandleRequestfor_apiname_ipaddress_token(w, r)
+}
+
+// TotpEnrolHandler handles POST /TotpEnrol. It creates a new (unconfirmed)
+// secret for the authenticated employee and returns the otpauth:// URI and
+// a QR code PNG for the authenticator app. An employee with a confirmed
+// enrolment must send a current code from the enrolled app to replace it;
+// without one the request is refused with 409. The enrolled app keeps
+// working until /TotpConfirm accepts a code from the new one.
+func TotpEnrolHandler(w http.ResponseWriter, r *http.Request) {
+	var req TotpRequest
+	if !readTotpRequest(w, r, &req) {
+		return
+	}
+
+	loggedHandler := auth.LogRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		employeeId := auth.EmployeeIdFromContext(r.Context())
+		username := auth.UsernameFromContext(r.Context())
+
+		enrolled, err := totpEnrolled(employeeId)
+		if err != nil {
+			log.Printf("Error checking TOTP enrolment: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if enrolled {
+			if req.OTP == "" {
+				http.Error(w, "Authenticator app already enrolled; send a current code from it to replace it", http.StatusConflict)
+				return
+			}
+			// Wrong codes count towards the lockout like a login attempt
+			err := checkTotp(r.Context(), employeeId, username, req.OTP.Normalize(otp.TOTPDigits), true, nil)
+			var rej *otp.Rejection
+			if errors.As(err, &rej) {
+				sendOTPRejection(w, rej)
+				return
+			}
+			if err != nil {
+				log.Printf("Error checking TOTP code: %v", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		secret, err := otp.NewTOTPSecret()
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if err := saveTotpSecret(employeeId, secret); err != nil {
+			log.Printf("Error saving TOTP secret: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		uri := otp.TOTPURI(totpIssuer, username, secret)
+		png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+		if err != nil {
+			log.Printf("Error generating QR code: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		sendEncryptedResponse(w, map[string]interface{}{
+			"message":     "Scan the QR code and confirm with a code from the app",
+			"otpauth_uri": uri,
+			"qr_png":      base64.StdEncoding.EncodeToString(png),
+			"secret":      secret, // for manual entry in the app
+		})
+	}))
+	loggedHandler.ServeHTTP(w, r)
+}
+
+// TotpConfirmHandler handles POST /TotpConfirm. A correct code activates the
+// pending enrolment, replacing any confirmed one, and makes TOTP the
+// preferred factor. Wrong codes count towards the lockout.
+func TotpConfirmHandler(w http.ResponseWriter, r *http.Request) {
+	var req TotpRequest
+	if !readTotpRequest(w, r, &req) {
+		return
+	}
+
+	loggedHandler := auth.LogRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if req.OTP == "" {
+			http.Error(w, "Missing required field: otp", http.StatusBadRequest)
+			return
+		}
+
+		employeeId := auth.EmployeeIdFromContext(r.Context())
+		username := auth.UsernameFromContext(r.Context())
+		err := checkTotp(r.Context(), employeeId, username, req.OTP.Normalize(otp.TOTPDigits), false, nil)
+		var rej *otp.Rejection
+		if errors.As(err, &rej) {
+			sendOTPRejection(w, rej)
+			return
+		}
+		if err != nil {
+			log.Printf("Error confirming TOTP: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if err := setPreferredFactor(employeeId, FactorTOTP); err != nil {
+			log.Printf("Error saving preferred factor: %v", err)
+		}
+
+		sendEncryptedResponse(w, map[string]interface{}{
+			"success":          true,
+			"message":          "Authenticator app enrolled",
+			"preferred_factor": FactorTOTP,
+		})
+	}))
+	loggedHandler.ServeHTTP(w, r)
+}
+
+// TotpVerifyHandler handles POST /TotpVerify, the login step used instead of
+// /Loginotp and /Loginotpupdate by employees who prefer the authenticator
+// app. It requires the pre-auth token returned by /HRldap.
+func TotpVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	var req TotpRequest
+	if !readTotpRequest(w, r, &req) {
+		return
+	}
+
+	loggedHandler := auth.LogRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if req.OTP == "" {
+			http.Error(w, "Missing required field: otp", http.StatusBadRequest)
+			return
+		}
+
+		// Username and session come from the pre-auth token
+		txn, err := loginTransactionFromRequest(r, loginLdapPassed, loginOTPSent, loginOTPVerified)
+		if err != nil {
+			sendLoginTransactionError(w, err)
+			return
+		}
+
+		// A code that already passed only needs the session created
+		if txn.State != loginOTPVerified {
+			err = checkTotp(r.Context(), txn.EmployeeID, txn.Username, req.OTP.Normalize(otp.TOTPDigits), true, txn)
+			var rej *otp.Rejection
+			if errors.As(err, &rej) {
+				sendOTPRejection(w, rej)
+				return
+			}
+			if err != nil {
+				sendLoginTransactionError(w, err)
+				return
+			}
+		}
+
+		// Create the session and exchange the pre-auth token for its JWT
+		completeLogin(w, r, txn)
+	}))
+	loggedHandler.ServeHTTP(w, r)
+}
+
+// MfaPreferenceHandler handles POST /MfaPreference. With preferred_factor set
+// it updates the caller's preference; it always returns the current one.
+func MfaPreferenceHandler(w http.ResponseWriter, r *http.Request) {
+	var req TotpRequest
+	if !readTotpRequest(w, r, &req) {
+		return
+	}
+
+	loggedHandler := auth.LogRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		employeeId := auth.EmployeeIdFromContext(r.Context())
+
+		switch req.PreferredFactor {
+		case "":
+		case FactorSMS:
+			if err := setPreferredFactor(employeeId, FactorSMS); err != nil {
+				log.Printf("Error saving preferred factor: %v", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+		case FactorTOTP:
+			enrolled, err := totpEnrolled(employeeId)
+			if err != nil {
+				log.Printf("Error checking TOTP enrolment: %v", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			if !enrolled {
+				http.Error(w, "Authenticator app is not enrolled", http.StatusBadRequest)
+				return
+			}
+			if err := setPreferredFactor(employeeId, FactorTOTP); err != nil {
+				log.Printf("Error saving preferred factor: %v", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+		default:
+			http.Error(w, "preferred_factor must be sms or totp", http.StatusBadRequest)
+			return
+		}
+
+		factor, err := getPreferredFactor(employeeId)
+		if err != nil {
+			log.Printf("Error reading preferred factor: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		sendEncryptedResponse(w, map[string]interface{}{
+			"preferred_factor": factor,
+		})
+	}))
+	loggedHandler.ServeHTTP(w, r)
+}
+
+// saveTotpSecret stores a new unconfirmed secret. A confirmed enrolment is
+// kept, with the new secret pending beside it, until checkTotp confirms the
+// new one; an unconfirmed one is replaced.
+func saveTotpSecret(employeeId, secret string) error {
+	encrypted, err := utils.Encrypt([]byte(secret))
+	if err != nil {
+		return fmt.Errorf("encrypt error: %v", err)
+	}
+
+	db := meivanDB
+
+	query := `
+		INSERT INTO employee_totp (employee_id, secret_enc, created_on, confirmed_on, last_counter)
+		VALUES ($1, $2, NOW(), NULL, 0)
+		ON CONFLICT (employee_id) DO UPDATE SET
+			secret_enc = CASE WHEN employee_totp.confirmed_on IS NULL
+				THEN EXCLUDED.secret_enc ELSE employee_totp.secret_enc END,
+			created_on = CASE WHEN employee_totp.confirmed_on IS NULL
+				THEN NOW() ELSE employee_totp.created_on END,
+			pending_secret_enc = CASE WHEN employee_totp.confirmed_on IS NULL
+				THEN NULL ELSE EXCLUDED.secret_enc END,
+			pending_created_on = CASE WHEN employee_totp.confirmed_on IS NULL
+				THEN NULL ELSE NOW() END`
+
+	if _, err := db.Exec(query, employeeId, encrypted); err != nil {
+		return fmt.Errorf("save TOTP secret error: %v", err)
+	}
+	return nil
+}
+
+// checkTotp validates code against the employee's secret and records the
+// used time step. With confirmed set only a confirmed enrolment is accepted
+// (login); otherwise the pending enrolment, or a pending replacement of a
+// confirmed one, is confirmed by a correct code.
+// Wrong codes during login count towards the username lockout. With txn set,
+// the used time step is committed together with txn moving to otp_verified.
+func checkTotp(ctx context.Context, employeeId, username, code string, confirmed bool, txn *loginTransaction) error {
+	db := meivanDB
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin error: %v", err)
+	}
+	defer tx.Rollback()
+
+	if username != "" {
+		if rej, err := checkOTPLockout(tx, username, ""); err != nil || rej != nil {
+			return firstError(rej, err)
+		}
+	}
+
+	var encrypted string
+	var pending sql.NullString
+	var lastCounter int64
+	var isConfirmed bool
+	err = tx.QueryRow(`
+		SELECT secret_enc, pending_secret_enc, last_counter, confirmed_on IS NOT NULL
+		FROM employee_totp
+		WHERE employee_id = $1
+		FOR UPDATE`, employeeId).Scan(&encrypted, &pending, &lastCounter, &isConfirmed)
+	if err == sql.ErrNoRows || (err == nil && confirmed && !isConfirmed) {
+		return otp.Reject(otp.ReasonNotFound, "Authenticator app not enrolled", 0)
+	}
+	if err != nil {
+		return fmt.Errorf("lookup error: %v", err)
+	}
+
+	// Confirming a replacement checks the code of the new app
+	promote := !confirmed && pending.Valid
+	if promote {
+		encrypted = pending.String
+	}
+
+	secret, err := utils.Decrypt(encrypted)
+	if err != nil {
+		return fmt.Errorf("decrypt error: %v", err)
+	}
+
+	step, ok := otp.ValidateTOTP(string(secret), code, time.Now(), lastCounter)
+	if !ok {
+		var rej *otp.Rejection
+		if username != "" {
+			if rej, err = recordOTPFailure(tx, username, ""); err != nil {
+				return err
+			}
+			if err := tx.Commit(); err != nil {
+				return fmt.Errorf("commit error: %v", err)
+			}
+		}
+		if rej != nil {
+			return rej
+		}
+		return otp.Reject(otp.ReasonInvalid, "Invalid code", 0)
+	}
+
+	_, err = tx.Exec(`UPDATE employee_totp
+		SET last_counter = $2,
+			confirmed_on = CASE WHEN $3 THEN NOW() ELSE COALESCE(confirmed_on, NOW()) END,
+			secret_enc = CASE WHEN $3 THEN pending_secret_enc ELSE secret_enc END,
+			created_on = CASE WHEN $3 THEN pending_created_on ELSE created_on END,
+			pending_secret_enc = CASE WHEN $3 THEN NULL ELSE pending_secret_enc END,
+			pending_created_on = CASE WHEN $3 THEN NULL ELSE pending_created_on END
+		WHERE employee_id = $1`, employeeId, step, promote)
+	if err != nil {
+		return fmt.Errorf("update error: %v", err)
+	}
+	if username != "" {
+		if err := clearOTPFailures(tx, username, ""); err != nil {
+			return err
+		}
+	}
+	if txn != nil {
+		if err := advanceLoginTransaction(tx, txn, loginOTPVerified, loginLdapPassed, loginOTPSent); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit error: %v", err)
+	}
+	return nil
+}
+
+// totpEnrolled reports whether the employee has a confirmed TOTP enrolment.
+func totpEnrolled(employeeId string) (bool, error) {
+	db := meivanDB
+
+	var enrolled bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM employee_totp
+		WHERE employee_id = $1 AND confirmed_on IS NOT NULL)`, employeeId).Scan(&enrolled)
+	if err != nil {
+		return false, fmt.Errorf("lookup error: %v", err)
+	}
+	return enrolled, nil
+}
+
+// getPreferredFactor returns the employee's preferred second factor, "sms" by default.
+func getPreferredFactor(employeeId string) (string, error) {
+	db := meivanDB
+
+	var factor string
+	err := db.QueryRow(`SELECT preferred_factor FROM employee_mfa_preference WHERE employee_id = $1`,
+		employeeId).Scan(&factor)
+	if err == sql.ErrNoRows {
+		return FactorSMS, nil
+	}
+	if err != nil {
+		return FactorSMS, fmt.Errorf("lookup error: %v", err)
+	}
+	return factor, nil
+}
+
+// setPreferredFactor stores the employee's preferred second factor.
+func setPreferredFactor(employeeId, factor string) error {
+	db := meivanDB
+
+	_, err := db.Exec(`
+		INSERT INTO employee_mfa_preference (employee_id, preferred_factor, updated_on)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (employee_id) DO UPDATE
+		SET preferred_factor = EXCLUDED.preferred_factor, updated_on = NOW()`, employeeId, factor)
+	if err != nil {
+		return fmt.Errorf("save preference error: %v", err)
+	}
+	return nil
+}
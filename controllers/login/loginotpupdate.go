@@ -4,9 +4,10 @@
 //
 // It ensures:
 //   - Secure request validation using token-based authentication
-//   - Validation of OTP details (username, mobile number, session ID, etc.)
-//   - Automatic expiry check for OTPs using validity window
+//   - Validation of the code against the stored salted hash in constant time
+//   - Expiry, attempt limit and lockout checks from otp.CurrentPolicy
 //   - Updates OTP status and verification timestamp on successful validation
+//   - Exchanges the pre-auth token for the session JWT only after the code passes
 //   - Encrypted response payloads for added security
 //
 // --- Creator's Info ---
@@ -17,28 +18,31 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 26-08-2025
+// Last Modified Date: 17-10-2026
 package controllerslogin
 
 import (
 	"Hrmodule/auth"
-	credentials "Hrmodule/dbconfig"
+	"Hrmodule/otp"
 	"Hrmodule/utils"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	_ "github.com/lib/pq"
 )
 
 // ValidateOTPRequest represents the request body for OTP validation
 type ValidateOTPRequest struct {
-	Token     string `json:"token"`
-	Username  string `json:"username"`
-	MobileNo  int64  `json:"mobileno"`
-	SessionID string `json:"session_id"`
-	OTP       int    `json:"otp"`
+	Token     string   `json:"token"`
+	Username  string   `json:"username"`   // Ignored; taken from the pre-auth token
+	MobileNo  int64    `json:"mobileno"`   // Not used for lookup; the OTP row holds the number on record
+	SessionID string   `json:"session_id"` // Ignored; taken from the pre-auth token
+	OTP       otp.Code `json:"otp"`
 }
 
 // ValidateOTPHandler validates OTP using ValidCheck logic
@@ -46,7 +50,7 @@ func ValidateOTPHandler(w http.ResponseWriter, r *http.Request) {
 	// Step 1: Parse request body
 	var req ValidateOTPRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, "Invalid JSON input", http.StatusBadRequest)
 		return
 	}
 
@@ -68,101 +72,134 @@ func ValidateOTPHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Step 5: Validate required fields
-		if req.Username == "" || req.MobileNo == 0 || req.SessionID == "" || req.OTP == 0 {
-			http.Error(w, "username, mobileno, session_id and otp are required", http.StatusBadRequest)
+		if req.OTP == "" {
+			http.Error(w, "otp is required", http.StatusBadRequest)
 			return
 		}
 
-		// Step 6: DB Connection
-		connectionString := credentials.Getdatabasemeivan()
-		db, err := sql.Open("postgres", connectionString)
+		// Step 6: Username and session come from the pre-auth token
+		txn, err := loginTransactionFromRequest(r, loginOTPSent, loginOTPVerified)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("DB open error: %v", err), http.StatusInternalServerError)
+			sendLoginTransactionError(w, err)
 			return
 		}
-		defer db.Close()
-
-		// Step 7: Check ValidCheck logic with OTP validation
-		checkQuery := `
-			SELECT 
-				id,
-				CASE WHEN (otpverifiedon IS NULL AND status = 0 AND otpvalidtill >= NOW()) 
-					THEN '1' 
-					ELSE '0' 
-				END as validcheck
-			FROM otp_details 
-			WHERE username = $1 
-			  AND mobileno = $2 
-			  AND session_id = $3 
-			  AND otp = $4
-			  AND status = 0
-			  AND otpverifiedon IS NULL 
-			ORDER BY otpsendon DESC 
-			LIMIT 1;
-		`
-
-		var id int
-		var validCheck string
-
-		err = db.QueryRow(checkQuery, req.Username, req.MobileNo, req.SessionID, req.OTP).Scan(&id, &validCheck)
 
-		if err != nil {
-			if err == sql.ErrNoRows {
-				// No matching record found
-				resp := map[string]interface{}{
-					"success":    false,
-					"message":    "Invalid OTP or OTP not found",
-					"validcheck": "0",
-				}
-				sendEncryptedResponse(w, resp)
+		// Step 7: Verify against the latest pending OTP under the OTP policy,
+		// unless it already passed and only the session is missing
+		if txn.State == loginOTPSent {
+			err = verifyOTP(r.Context(), txn, req.OTP.Normalize(otp.CurrentPolicy.Length))
+			var rej *otp.Rejection
+			if errors.As(err, &rej) {
+				sendOTPRejection(w, rej)
 				return
 			}
-			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Step 8: Check if validcheck = 1 (needs update)
-		if validCheck == "1" {
-			// Update otpverifiedon and status
-			updateQuery := `
-				UPDATE otp_details 
-				SET otpverifiedon = NOW(), status = 1
-				WHERE id = $1
-			`
-
-			_, err = db.Exec(updateQuery, id)
 			if err != nil {
-				http.Error(w, "Error updating OTP verification: "+err.Error(), http.StatusInternalServerError)
+				sendLoginTransactionError(w, err)
 				return
 			}
-
-			// Success response
-			resp := map[string]interface{}{
-				"success":    true,
-				"message":    "OTP verified successfully",
-				"validcheck": "1",
-				"username":   req.Username,
-				"mobileno":   req.MobileNo,
-				"session_id": req.SessionID,
-			}
-			sendEncryptedResponse(w, resp)
-		} else {
-			// validcheck = 0, no update needed
-			resp := map[string]interface{}{
-				"success":    false,
-				"message":    "OTP expired or invalid",
-				"validcheck": "0",
-			}
-			sendEncryptedResponse(w, resp)
 		}
+
+		// Step 8: Create the session and exchange the pre-auth token for its JWT
+		completeLogin(w, r, txn)
 	}))
 
 	// Run the logged handler
 	loggedHandler.ServeHTTP(w, r)
 }
 
+// verifyOTP checks code against the latest pending OTP of the login
+// transaction. Wrong guesses count against the OTP's attempts and against the
+// username and mobile lockout. It returns a *otp.Rejection when the code is
+// not accepted. An accepted code is used up in the same database
+// transaction that moves txn to otp_verified.
+func verifyOTP(ctx context.Context, txn *loginTransaction, code string) error {
+	policy := otp.CurrentPolicy
+	username, sessionId := txn.Username, txn.ID
+
+	db := meivanDB
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin error: %v", err)
+	}
+	defer tx.Rollback()
+
+	checkQuery := `
+		SELECT 
+			id,
+			COALESCE(otp_hash, ''),
+			mobileno::text,
+			attempts,
+			EXTRACT(EPOCH FROM otpvalidtill - NOW())
+		FROM otp_details 
+		WHERE username = $1 
+		  AND session_id = $2 
+		  AND status = 0
+		  AND otpverifiedon IS NULL 
+		ORDER BY otpsendon DESC 
+		LIMIT 1
+		FOR UPDATE;
+	`
+
+	var id, attempts int
+	var otpHash, mobileNo string
+	var remaining float64
+	err = tx.QueryRow(checkQuery, username, sessionId).Scan(&id, &otpHash, &mobileNo, &attempts, &remaining)
+	if err == sql.ErrNoRows {
+		return otp.Reject(otp.ReasonNotFound, "Invalid OTP or OTP not found", 0)
+	}
+	if err != nil {
+		return fmt.Errorf("lookup error: %v", err)
+	}
+
+	if rej, err := checkOTPLockout(tx, username, mobileNo); err != nil || rej != nil {
+		return firstError(rej, err)
+	}
+	if rej := policy.CheckVerify(attempts, time.Duration(remaining*float64(time.Second))); rej != nil {
+		return rej
+	}
+
+	if !otp.Verify(code, otpHash) {
+		if _, err := tx.Exec(`UPDATE otp_details SET attempts = attempts + 1 WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("update attempts error: %v", err)
+		}
+		rej, err := recordOTPFailure(tx, username, mobileNo)
+		if err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit error: %v", err)
+		}
+		if rej != nil {
+			return rej
+		}
+		left := policy.MaxVerifyAttempts - attempts - 1
+		return otp.Reject(otp.ReasonInvalid, fmt.Sprintf("Invalid OTP, %d attempt(s) left", left), 0)
+	}
+
+	// Update otpverifiedon and status
+	if _, err := tx.Exec(`UPDATE otp_details SET otpverifiedon = NOW(), status = 1 WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("update OTP verification error: %v", err)
+	}
+	if err := clearOTPFailures(tx, username, mobileNo); err != nil {
+		return err
+	}
+	if err := advanceLoginTransaction(tx, txn, loginOTPVerified, loginOTPSent); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit error: %v", err)
+	}
+	return nil
+}
+
 // Helper function to send encrypted response
 func sendEncryptedResponse(w http.ResponseWriter, resp map[string]interface{}) {
+	sendEncryptedStatus(w, http.StatusOK, resp)
+}
+
+// sendEncryptedStatus sends an encrypted response with the given HTTP status code
+func sendEncryptedStatus(w http.ResponseWriter, statusCode int, resp map[string]interface{}) {
 	// Marshal to JSON
 	jsonResponse, err := json.MarshalIndent(resp, "", "    ")
 	if err != nil {
@@ -179,6 +216,7 @@ func sendEncryptedResponse(w http.ResponseWriter, resp map[string]interface{}) {
 
 	// Send encrypted response
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
 	_ = json.NewEncoder(w).Encode(map[string]string{
 		"Data": encrypted,
 	})
@@ -0,0 +1,125 @@
+// Package controllerslogin provides enforcement of the OTP policy
+// (otp.CurrentPolicy) against otp_details and otp_lockout.
+//
+// It ensures:
+//   - A temporary lockout per username and per mobile number after repeated failures
+//   - Every rejection carries a reason code and a retry-after value
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package controllerslogin
+
+import (
+	"Hrmodule/otp"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// sqlRunner is satisfied by both *sql.DB and *sql.Tx.
+type sqlRunner interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// checkOTPLockout returns a rejection if the username or mobile number is locked.
+func checkOTPLockout(q sqlRunner, username, mobileNo string) (*otp.Rejection, error) {
+	query := `
+		SELECT EXTRACT(EPOCH FROM MAX(locked_until) - NOW())
+		FROM otp_lockout
+		WHERE ((subject_type = 'username' AND subject = $1)
+		    OR (subject_type = 'mobileno' AND subject = $2))
+		  AND locked_until > NOW()`
+
+	var remaining sql.NullFloat64
+	if err := q.QueryRow(query, username, mobileNo).Scan(&remaining); err != nil {
+		return nil, fmt.Errorf("lockout lookup error: %v", err)
+	}
+	if !remaining.Valid {
+		return nil, nil
+	}
+	return otp.Reject(otp.ReasonLocked, "Too many failed attempts, try again later",
+		time.Duration(remaining.Float64*float64(time.Second))), nil
+}
+
+// recordOTPFailure counts a failed verification against the username and the
+// mobile number and locks whichever reaches the threshold.
+func recordOTPFailure(q sqlRunner, username, mobileNo string) (*otp.Rejection, error) {
+	policy := otp.CurrentPolicy
+	window := int(policy.LockoutDuration.Seconds())
+
+	upsert := `
+		INSERT INTO otp_lockout (subject_type, subject, failures, window_start)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (subject_type, subject) DO UPDATE SET
+			failures = CASE WHEN otp_lockout.window_start < NOW() - $3 * interval '1 second'
+				THEN 1 ELSE otp_lockout.failures + 1 END,
+			window_start = CASE WHEN otp_lockout.window_start < NOW() - $3 * interval '1 second'
+				THEN NOW() ELSE otp_lockout.window_start END
+		RETURNING failures`
+
+	lock := `
+		UPDATE otp_lockout
+		SET locked_until = NOW() + $3 * interval '1 second', failures = 0, window_start = NOW()
+		WHERE subject_type = $1 AND subject = $2`
+
+	locked := false
+	for _, subject := range [][2]string{{"username", username}, {"mobileno", mobileNo}} {
+		if subject[1] == "" {
+			continue
+		}
+		var failures int
+		if err := q.QueryRow(upsert, subject[0], subject[1], window).Scan(&failures); err != nil {
+			return nil, fmt.Errorf("record failure error: %v", err)
+		}
+		if failures >= policy.LockoutThreshold {
+			if _, err := q.Exec(lock, subject[0], subject[1], window); err != nil {
+				return nil, fmt.Errorf("lockout error: %v", err)
+			}
+			locked = true
+		}
+	}
+
+	if locked {
+		return otp.Reject(otp.ReasonLocked, "Too many failed attempts, try again later", policy.LockoutDuration), nil
+	}
+	return nil, nil
+}
+
+// clearOTPFailures resets the failure counters after a successful verification.
+// Active lockouts are left in place.
+func clearOTPFailures(q sqlRunner, username, mobileNo string) error {
+	query := `
+		DELETE FROM otp_lockout
+		WHERE ((subject_type = 'username' AND subject = $1)
+		    OR (subject_type = 'mobileno' AND subject = $2))
+		  AND (locked_until IS NULL OR locked_until <= NOW())`
+
+	if _, err := q.Exec(query, username, mobileNo); err != nil {
+		return fmt.Errorf("clear failures error: %v", err)
+	}
+	return nil
+}
+
+// sendOTPRejection writes a policy rejection in the encrypted envelope.
+func sendOTPRejection(w http.ResponseWriter, rej *otp.Rejection) {
+	if rej.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(rej.RetryAfterSeconds()))
+	}
+	sendEncryptedStatus(w, rej.StatusCode(), map[string]interface{}{
+		"success":     false,
+		"validcheck":  "0",
+		"reason":      rej.Reason,
+		"message":     rej.Message,
+		"retry_after": rej.RetryAfterSeconds(),
+	})
+}
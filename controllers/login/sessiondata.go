@@ -7,7 +7,7 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 25-08-2025
+// Last Modified Date: 17-10-2026
 package controllerslogin
 
 import (
@@ -29,7 +29,8 @@ type APIResponseforSessionData struct {
 
 // Struct for token injection
 type SessionDataRequest struct {
-	Token string `json:"token"`
+	Token     string `json:"token"`
+	SessionID string `json:"Session_id"`
 }
 
 // SessionData handles POST API for session_data
@@ -66,6 +67,12 @@ func SessionData(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Only HR admins may read a session other than their own
+		if req.SessionID != auth.SessionFromContext(r.Context()) && !auth.Can(r.Context(), auth.PermSessionReadAny) {
+			auth.RespondForbidden(w, "Not allowed to view another session")
+			return
+		}
+
 		// DB query
 		sessionDataList, totalCount, err := databaselogin.SessionDatadatabase(w, r)
 		if err != nil {
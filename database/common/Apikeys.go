@@ -0,0 +1,253 @@
+// Package databasecommon reads and maintains the API keys, vendors, API
+// grants and IP allowlists used by auth.ValidateAPI, and logs validated
+// requests to Client_Request.
+//
+// All of it lives in the API validation (MySQL) database.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package databasecommon
+
+import (
+	modelscommon "Hrmodule/models/common"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql" // MySQL driver
+)
+
+// Errors returned by the key maintenance functions.
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found or not active")
+	ErrVendorNotFound = errors.New("vendor not found")
+	ErrVendorInactive = errors.New("vendor is not active")
+)
+
+// APIWindow is an activation flag with an optional validity period.
+// Zero From or Until means the period is open on that side.
+type APIWindow struct {
+	Active bool
+	From   time.Time
+	Until  time.Time
+}
+
+// OpenAt reports whether the window is active at t.
+func (w APIWindow) OpenAt(t time.Time) bool {
+	if !w.Active {
+		return false
+	}
+	if !w.From.IsZero() && t.Before(w.From) {
+		return false
+	}
+	return w.Until.IsZero() || t.Before(w.Until)
+}
+
+// APIGrant is an API granted to a vendor. Both the API itself and the
+// vendor's grant must be open for a call to pass.
+type APIGrant struct {
+	API   APIWindow
+	Grant APIWindow
+}
+
+// APIKeyAccess is everything needed to validate a request made with one key
+// or, on the mutual-TLS listener, one client certificate (see
+// ClientCertByFingerprint); for a certificate the Key fields describe it.
+type APIKeyAccess struct {
+	KeyId      int64
+	VendorId   int64
+	VendorName string
+	KeyPrefix  string
+	KeyActive  bool
+	KeyExpires time.Time // zero: no expiry
+	Vendor     APIWindow
+	APIs       map[string]APIGrant // by api_name
+	CIDRs      []string            // allowlist entries as stored
+}
+
+// unixTime converts a nullable UNIX_TIMESTAMP to a time, zero for NULL.
+func unixTime(v sql.NullInt64) time.Time {
+	if !v.Valid {
+		return time.Time{}
+	}
+	return time.Unix(v.Int64, 0)
+}
+
+// APIKeyByHash loads the key with the given hash together with its vendor,
+// API grants and IP allowlist. It returns ErrAPIKeyNotFound for an unknown key.
+func APIKeyByHash(keyHash string) (*APIKeyAccess, error) {
+	db := apiValidationDB
+
+	var (
+		a                      APIKeyAccess
+		keyExpires             sql.NullInt64
+		vendorFrom, vendorTill sql.NullInt64
+	)
+	err := db.QueryRow(modelscommon.MyQueryAPIKeyByHash, keyHash).Scan(
+		&a.KeyId, &a.VendorId, &a.VendorName, &a.KeyPrefix,
+		&a.KeyActive, &keyExpires,
+		&a.Vendor.Active, &vendorFrom, &vendorTill)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading api key: %v", err)
+	}
+	a.KeyExpires = unixTime(keyExpires)
+	a.Vendor.From, a.Vendor.Until = unixTime(vendorFrom), unixTime(vendorTill)
+
+	if err := loadVendorAccess(db, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// loadVendorAccess fills in the API grants and IP allowlist of a.VendorId.
+func loadVendorAccess(db *sql.DB, a *APIKeyAccess) error {
+	rows, err := db.Query(modelscommon.MyQueryVendorAPIs, a.VendorId)
+	if err != nil {
+		return fmt.Errorf("error reading vendor apis: %v", err)
+	}
+	defer rows.Close()
+
+	a.APIs = make(map[string]APIGrant)
+	for rows.Next() {
+		var (
+			name                                   string
+			g                                      APIGrant
+			apiFrom, apiTill, grantFrom, grantTill sql.NullInt64
+		)
+		if err := rows.Scan(&name,
+			&g.API.Active, &apiFrom, &apiTill,
+			&g.Grant.Active, &grantFrom, &grantTill); err != nil {
+			return fmt.Errorf("error scanning vendor api: %v", err)
+		}
+		g.API.From, g.API.Until = unixTime(apiFrom), unixTime(apiTill)
+		g.Grant.From, g.Grant.Until = unixTime(grantFrom), unixTime(grantTill)
+		a.APIs[name] = g
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading vendor apis: %v", err)
+	}
+
+	ipRows, err := db.Query(modelscommon.MyQueryVendorIPs, a.VendorId)
+	if err != nil {
+		return fmt.Errorf("error reading vendor allowlist: %v", err)
+	}
+	defer ipRows.Close()
+
+	for ipRows.Next() {
+		var cidr string
+		if err := ipRows.Scan(&cidr); err != nil {
+			return fmt.Errorf("error scanning vendor allowlist: %v", err)
+		}
+		a.CIDRs = append(a.CIDRs, cidr)
+	}
+	if err := ipRows.Err(); err != nil {
+		return fmt.Errorf("error reading vendor allowlist: %v", err)
+	}
+	return nil
+}
+
+// InsertAPIKey stores a new key for an active vendor and returns its id.
+// A ttl of 0 issues a key that does not expire.
+func InsertAPIKey(vendorId int64, keyHash, keyPrefix, createdBy string, ttl time.Duration) (int64, error) {
+	db := apiValidationDB
+
+	var active bool
+	err := db.QueryRow(modelscommon.MyQueryVendorIsActive, vendorId).Scan(&active)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrVendorNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading vendor: %v", err)
+	}
+	if !active {
+		return 0, ErrVendorInactive
+	}
+
+	seconds := int64(ttl.Seconds())
+	res, err := db.Exec(modelscommon.MyQueryInsertAPIKey,
+		vendorId, keyHash, keyPrefix, createdBy, seconds, seconds)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting api key: %v", err)
+	}
+	return res.LastInsertId()
+}
+
+// RotateAPIKey issues a new key for the vendor of keyId and lets the old key
+// run for grace more at most. It returns the new key id and the vendor id.
+func RotateAPIKey(keyId int64, keyHash, keyPrefix, createdBy string, ttl, grace time.Duration) (int64, int64, error) {
+	db := apiValidationDB
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin error: %v", err)
+	}
+	defer tx.Rollback()
+
+	var vendorId int64
+	err = tx.QueryRow(modelscommon.MyQueryAPIKeyForUpdate, keyId).Scan(&vendorId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("error reading api key: %v", err)
+	}
+
+	seconds := int64(ttl.Seconds())
+	res, err := tx.Exec(modelscommon.MyQueryInsertAPIKey,
+		vendorId, keyHash, keyPrefix, createdBy, seconds, seconds)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error inserting api key: %v", err)
+	}
+	newId, err := res.LastInsertId()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error reading new key id: %v", err)
+	}
+
+	graceSeconds := int64(grace.Seconds())
+	if _, err := tx.Exec(modelscommon.MyQueryRetireAPIKey, newId, graceSeconds, graceSeconds, keyId); err != nil {
+		return 0, 0, fmt.Errorf("error retiring api key: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("commit error: %v", err)
+	}
+	return newId, vendorId, nil
+}
+
+// RevokeAPIKey deactivates keyId at once. It returns ErrAPIKeyNotFound if
+// the key does not exist or is already inactive.
+func RevokeAPIKey(keyId int64, revokedBy string) error {
+	db := apiValidationDB
+
+	res, err := db.Exec(modelscommon.MyQueryRevokeAPIKey, revokedBy, keyId)
+	if err != nil {
+		return fmt.Errorf("error revoking api key: %v", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// LogClientRequest records a validated request in Client_Request.
+// errorMessage is empty when the request was accepted.
+func LogClientRequest(clientIP, requestURL, status, errorMessage string) error {
+	db := apiValidationDB
+
+	if _, err := db.Exec(modelscommon.MyQueryInsertClientRequest,
+		clientIP, requestURL, status, errorMessage); err != nil {
+		return fmt.Errorf("error logging client request: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Package databasecommon maps the client certificates presented on the
+// mutual-TLS listener to vendors, see 014_client_certs.sql.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package databasecommon
+
+import (
+	modelscommon "Hrmodule/models/common"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrClientCertNotFound is returned for a certificate that is not registered.
+var ErrClientCertNotFound = errors.New("client certificate not registered")
+
+// ClientCertByFingerprint loads the registered certificate with the given
+// fingerprint together with its vendor, API grants and IP allowlist. The
+// result uses the key fields of APIKeyAccess for the certificate: KeyId is
+// the cert_id and KeyPrefix the start of the fingerprint.
+func ClientCertByFingerprint(fingerprint string) (*APIKeyAccess, error) {
+	db := apiValidationDB
+
+	var (
+		a                      APIKeyAccess
+		certExpires            sql.NullInt64
+		vendorFrom, vendorTill sql.NullInt64
+	)
+	err := db.QueryRow(modelscommon.MyQueryClientCertByFingerprint, fingerprint).Scan(
+		&a.KeyId, &a.VendorId, &a.VendorName, &a.KeyPrefix,
+		&a.KeyActive, &certExpires,
+		&a.Vendor.Active, &vendorFrom, &vendorTill)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrClientCertNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading client certificate: %v", err)
+	}
+	a.KeyExpires = unixTime(certExpires)
+	a.Vendor.From, a.Vendor.Until = unixTime(vendorFrom), unixTime(vendorTill)
+
+	if err := loadVendorAccess(db, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
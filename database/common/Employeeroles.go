@@ -8,13 +8,11 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 30-07-2025
+// Last Modified Date: 17-10-2026
 package databasecommon
 
 import (
-	credentials "Hrmodule/dbconfig"
 	modelscommon "Hrmodule/models/common"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -28,15 +26,7 @@ type DefaultRoleNameRequest struct {
 }
 
 func DefaultRoleNamedatabase(w http.ResponseWriter, r *http.Request) ([]modelscommon.DefaultRoleNamestructure, int, error) {
-	// Connection string for Postgres
-	connectionString := credentials.Getdatabasemeivan()
-
-	db, err := sql.Open("postgres", connectionString)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("DB open error: %v", err), http.StatusInternalServerError)
-		return nil, 0, err
-	}
-	defer db.Close()
+	db := meivanDB
 
 	/// Decode POST JSON body
 	var req DefaultRoleNameRequest
@@ -63,3 +53,24 @@ func DefaultRoleNamedatabase(w http.ResponseWriter, r *http.Request) ([]modelsco
 
 	return DefaultRoleNameapi, len(DefaultRoleNameapi), nil
 }
+
+// ActiveRoleNames returns the names of the roles currently active for a user.
+func ActiveRoleNames(username string) ([]string, error) {
+	db := meivanDB
+
+	rows, err := db.Query(modelscommon.MyQueryActiveRoleNames, username)
+	if err != nil {
+		return nil, fmt.Errorf("error querying database: %v", err)
+	}
+	defer rows.Close()
+
+	var roles []string
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
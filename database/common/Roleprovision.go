@@ -0,0 +1,175 @@
+// Package databasecommon handles provisioning of directory mapped roles
+// into ORGUNITUSERMAPPING.
+//
+// Only mappings created here (ProvisionSource set) are ever deactivated;
+// mappings entered manually by HR are left untouched.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package databasecommon
+
+import (
+	modelscommon "Hrmodule/models/common"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+// Actions recorded in role_provision_audit.
+const (
+	RoleGranted     = "granted"
+	RoleReactivated = "reactivated"
+	RoleRevoked     = "revoked"
+	RoleUnknown     = "unknown_role"
+)
+
+// userRoleMapping is a row of ORGUNITUSERMAPPING with its role name.
+type userRoleMapping struct {
+	roleMapId string
+	roleName  string
+	isActive  string
+	source    string
+}
+
+// mappedRole is a role name with its ORGUNITROLEMAPPING id, empty when
+// ROLEMASTER has no such role.
+type mappedRole struct {
+	name      string
+	roleMapId string
+}
+
+// roleChange is one change to ORGUNITUSERMAPPING and its audit row.
+type roleChange struct {
+	action    string // one of the Role constants
+	roleName  string
+	roleMapId string
+	detail    string
+}
+
+// planRoleChanges returns the changes that make the mappings from source in
+// existing match roles, in role order followed by the revocations sorted by
+// id. Active mappings and manual (HR) mappings are left as they are.
+func planRoleChanges(existing map[string]userRoleMapping, roles []mappedRole, source string) []roleChange {
+	var changes []roleChange
+	names := make([]string, 0, len(roles))
+	wanted := make(map[string]bool)
+	for _, role := range roles {
+		names = append(names, role.name)
+		if role.roleMapId == "" {
+			changes = append(changes, roleChange{RoleUnknown, role.name, "", "no ORGUNITROLEMAPPING for role"})
+			continue
+		}
+		if wanted[role.roleMapId] {
+			continue
+		}
+		wanted[role.roleMapId] = true
+
+		m, ok := existing[role.roleMapId]
+		switch {
+		case !ok:
+			changes = append(changes, roleChange{RoleGranted, role.name, role.roleMapId, ""})
+		case m.isActive != "1" && m.source == source:
+			changes = append(changes, roleChange{RoleReactivated, role.name, role.roleMapId, ""})
+		}
+	}
+
+	var revoked []string
+	for id, m := range existing {
+		if !wanted[id] && m.source == source && m.isActive == "1" {
+			revoked = append(revoked, id)
+		}
+	}
+	sort.Strings(revoked)
+	for _, id := range revoked {
+		changes = append(changes, roleChange{RoleRevoked, existing[id].roleName, id, "no longer mapped: " + strings.Join(names, ", ")})
+	}
+	return changes
+}
+
+// ProvisionRoles makes the roles provisioned from source for username match
+// roles: missing mappings are granted, inactive ones reactivated, and
+// mappings from source that are no longer mapped are deactivated. Every
+// change is audited. It reports whether any mapping changed.
+func ProvisionRoles(username string, roles []string, source string) (bool, error) {
+	db := meivanDB
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("begin error: %v", err)
+	}
+	defer tx.Rollback()
+
+	var userId string
+	err = tx.QueryRow(modelscommon.MyQueryUserIdByName, username).Scan(&userId)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("user %s not in USERMASTER", username)
+	}
+	if err != nil {
+		return false, fmt.Errorf("error querying user: %v", err)
+	}
+
+	rows, err := tx.Query(modelscommon.MyQueryUserRoleMappings, userId)
+	if err != nil {
+		return false, fmt.Errorf("error querying mappings: %v", err)
+	}
+	existing := make(map[string]userRoleMapping)
+	for rows.Next() {
+		var m userRoleMapping
+		if err := rows.Scan(&m.roleMapId, &m.roleName, &m.isActive, &m.source); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("error scanning row: %v", err)
+		}
+		existing[m.roleMapId] = m
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("error reading mappings: %v", err)
+	}
+
+	mapped := make([]mappedRole, 0, len(roles))
+	for _, role := range roles {
+		var roleMapId sql.NullString
+		if err := tx.QueryRow(modelscommon.MyQueryRoleMapIdByRoleName, role).Scan(&roleMapId); err != nil {
+			return false, fmt.Errorf("error querying role %s: %v", role, err)
+		}
+		mapped = append(mapped, mappedRole{name: role, roleMapId: roleMapId.String})
+	}
+
+	changed := false
+	for _, c := range planRoleChanges(existing, mapped, source) {
+		switch c.action {
+		case RoleGranted:
+			if _, err := tx.Exec(modelscommon.MyQueryInsertProvisionedRole, userId, c.roleMapId, source); err != nil {
+				return false, fmt.Errorf("error granting role %s: %v", c.roleName, err)
+			}
+		case RoleReactivated:
+			if _, err := tx.Exec(modelscommon.MyQuerySetProvisionedRoleActive, userId, c.roleMapId, "1", source); err != nil {
+				return false, fmt.Errorf("error reactivating role %s: %v", c.roleName, err)
+			}
+		case RoleRevoked:
+			if _, err := tx.Exec(modelscommon.MyQuerySetProvisionedRoleActive, userId, c.roleMapId, "0", source); err != nil {
+				return false, fmt.Errorf("error revoking role %s: %v", c.roleName, err)
+			}
+		}
+		if _, err := tx.Exec(modelscommon.MyQueryInsertRoleProvisionAudit, username, c.roleName, c.action, source, c.detail); err != nil {
+			return false, fmt.Errorf("error writing audit: %v", err)
+		}
+		// An unknown role is only audited
+		changed = changed || c.action != RoleUnknown
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit error: %v", err)
+	}
+	return changed, nil
+}
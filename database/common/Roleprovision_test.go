@@ -0,0 +1,85 @@
+package databasecommon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanRoleChanges(t *testing.T) {
+	const source = "ldap"
+	existing := map[string]userRoleMapping{
+		"10": {"10", "Staff", "1", source},    // active, provisioned
+		"11": {"11", "Faculty", "0", source},  // inactive, provisioned
+		"12": {"12", "HOD", "1", ""},          // active, entered by HR
+		"13": {"13", "Approver", "0", ""},     // inactive, entered by HR
+		"14": {"14", "Dean", "1", source},     // active, provisioned
+		"15": {"15", "Registrar", "1", "sso"}, // active, another source
+	}
+
+	tests := []struct {
+		name  string
+		roles []mappedRole
+		want  []roleChange
+	}{
+		{
+			name:  "nothing changes",
+			roles: []mappedRole{{"Staff", "10"}, {"Dean", "14"}},
+		},
+		{
+			name:  "grant a new role",
+			roles: []mappedRole{{"Staff", "10"}, {"Dean", "14"}, {"Warden", "20"}},
+			want:  []roleChange{{RoleGranted, "Warden", "20", ""}},
+		},
+		{
+			name:  "reactivate a provisioned role",
+			roles: []mappedRole{{"Staff", "10"}, {"Faculty", "11"}, {"Dean", "14"}},
+			want:  []roleChange{{RoleReactivated, "Faculty", "11", ""}},
+		},
+		{
+			name:  "HR mappings are left alone",
+			roles: []mappedRole{{"Staff", "10"}, {"HOD", "12"}, {"Approver", "13"}, {"Dean", "14"}},
+		},
+		{
+			name:  "revoke roles no longer mapped",
+			roles: []mappedRole{{"Staff", "10"}},
+			want:  []roleChange{{RoleRevoked, "Dean", "14", "no longer mapped: Staff"}},
+		},
+		{
+			name:  "no roles revokes every active provisioned role",
+			roles: nil,
+			want: []roleChange{
+				{RoleRevoked, "Staff", "10", "no longer mapped: "},
+				{RoleRevoked, "Dean", "14", "no longer mapped: "},
+			},
+		},
+		{
+			name:  "unknown role is only audited",
+			roles: []mappedRole{{"Staff", "10"}, {"Dean", "14"}, {"Chancellor", ""}},
+			want:  []roleChange{{RoleUnknown, "Chancellor", "", "no ORGUNITROLEMAPPING for role"}},
+		},
+		{
+			name:  "same role twice is granted once",
+			roles: []mappedRole{{"Staff", "10"}, {"Dean", "14"}, {"Warden", "20"}, {"warden", "20"}},
+			want:  []roleChange{{RoleGranted, "Warden", "20", ""}},
+		},
+		{
+			name:  "changes in role order, then revocations",
+			roles: []mappedRole{{"Warden", "20"}, {"Chancellor", ""}, {"Faculty", "11"}},
+			want: []roleChange{
+				{RoleGranted, "Warden", "20", ""},
+				{RoleUnknown, "Chancellor", "", "no ORGUNITROLEMAPPING for role"},
+				{RoleReactivated, "Faculty", "11", ""},
+				{RoleRevoked, "Staff", "10", "no longer mapped: Warden, Chancellor, Faculty"},
+				{RoleRevoked, "Dean", "14", "no longer mapped: Warden, Chancellor, Faculty"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planRoleChanges(existing, tt.roles, source)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planRoleChanges =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,9 +12,7 @@
 package databasecommon
 
 import (
-	credentials "Hrmodule/dbconfig"
 	modelscommon "Hrmodule/models/common"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -27,14 +25,7 @@ type StatusMasterRequest struct {
 
 // StatusMasterDatabase executes the query
 func StatusMasterDatabase(w http.ResponseWriter, r *http.Request) ([]modelscommon.StatusMaster, int, error) {
-	connectionString := credentials.Getdatabasemeivan()
-
-	db, err := sql.Open("postgres", connectionString)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("DB open error: %v", err), http.StatusInternalServerError)
-		return nil, 0, err
-	}
-	defer db.Close()
+	db := meivanDB
 
 	// Decode request body
 	var req StatusMasterRequest
@@ -12,9 +12,7 @@
 package databasecommon
 
 import (
-	credentials "Hrmodule/dbconfig"
 	modelscommon "Hrmodule/models/common"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -28,14 +26,7 @@ type InboxTasksRoleRequest struct {
 
 // InboxTasksRoleDatabase executes getinboxtasks_role
 func InboxTasksRoleDatabase(w http.ResponseWriter, r *http.Request) ([]modelscommon.InboxTasksRole, int, error) {
-	connectionString := credentials.Getdatabasemeivan()
-
-	db, err := sql.Open("postgres", connectionString)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("DB open error: %v", err), http.StatusInternalServerError)
-		return nil, 0, err
-	}
-	defer db.Close()
+	db := meivanDB
 
 	// Decode request
 	var req InboxTasksRoleRequest
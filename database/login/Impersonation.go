@@ -0,0 +1,56 @@
+// Package databaselogin writes the impersonation audit trail.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package databaselogin
+
+import (
+	modelslogin "Hrmodule/models/login"
+	"fmt"
+
+	_ "github.com/lib/pq"
+)
+
+// ImpersonationAudit is one row of impersonation_audit.
+type ImpersonationAudit struct {
+	ActorUsername    string // admin acting as the target
+	ActorEmployeeId  string
+	SessionId        string // the admin's session
+	TargetUsername   string
+	TargetEmployeeId string
+	Method           string
+	Route            string // request path, e.g. /TaskInbox or /Impersonate
+	Allowed          bool   // false when the route refused the token
+	ClientIP         string
+}
+
+// InsertImpersonationAudit records a, before it is served, and returns the row id.
+func InsertImpersonationAudit(a ImpersonationAudit) (int64, error) {
+	db := meivanDB
+
+	var id int64
+	err := db.QueryRow(modelslogin.MyQueryInsertImpersonationAudit,
+		a.ActorUsername, a.ActorEmployeeId, a.SessionId, a.TargetUsername, a.TargetEmployeeId,
+		a.Method, a.Route, a.Allowed, a.ClientIP).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error writing impersonation audit: %v", err)
+	}
+	return id, nil
+}
+
+// CompleteImpersonationAudit stores the status the audited request ended with.
+func CompleteImpersonationAudit(id int64, status int) error {
+	db := meivanDB
+
+	if _, err := db.Exec(modelslogin.MyQueryCompleteImpersonationAudit, id, status); err != nil {
+		return fmt.Errorf("error completing impersonation audit: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Package databaselogin handles the queries of the My Sessions page.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package databaselogin
+
+import (
+	modelslogin "Hrmodule/models/login"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	_ "github.com/lib/pq"
+)
+
+// ErrSessionNotFound is returned when a session does not exist.
+var ErrSessionNotFound = errors.New("session not found")
+
+// EmployeeSessions returns up to limit sessions of employeeId, active ones first.
+func EmployeeSessions(employeeId string, limit int) ([]modelslogin.MySessionStructure, error) {
+	db := meivanDB
+
+	rows, err := db.Query(modelslogin.MyQueryEmployeeSessions, employeeId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying database: %v", err)
+	}
+	defer rows.Close()
+
+	return modelslogin.RetrieveMySessions(rows)
+}
+
+// SessionOwner returns the employee a session belongs to and whether it is
+// still active. It returns ErrSessionNotFound for unknown sessions.
+func SessionOwner(sessionId string) (string, bool, error) {
+	db := meivanDB
+
+	var employeeId sql.NullString
+	var isActive int
+	err := db.QueryRow(modelslogin.MyQuerySessionOwner, sessionId).Scan(&employeeId, &isActive)
+	if err == sql.ErrNoRows {
+		return "", false, ErrSessionNotFound
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("error querying database: %v", err)
+	}
+	return employeeId.String, isActive == 1, nil
+}
@@ -0,0 +1,29 @@
+// Package databaselogin queries its databases through the shared pools that
+// main opens at startup and hands over with UsePools.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package databaselogin
+
+import (
+	credentials "Hrmodule/dbconfig"
+	"database/sql"
+)
+
+// Database pools used by this package, set by UsePools.
+var (
+	meivanDB *sql.DB // Meivan (Postgres)
+)
+
+// UsePools sets the database pools of this package. It must be called
+// before the first request is served.
+func UsePools(p *credentials.Pools) {
+	meivanDB = p.DB(credentials.PoolMeivan)
+}
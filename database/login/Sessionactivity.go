@@ -0,0 +1,74 @@
+// Package databaselogin handles the session_data queries of server-side
+// idle timeout.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package databaselogin
+
+import (
+	modelslogin "Hrmodule/models/login"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// IdleSession is an active session without activity for Idle.
+type IdleSession struct {
+	SessionId string
+	Username  string
+	Idle      time.Duration
+}
+
+// TouchSessions writes the last activity of each session in one statement.
+func TouchSessions(lastSeen map[string]time.Time) error {
+	db := meivanDB
+
+	ids := make([]string, 0, len(lastSeen))
+	seen := make([]float64, 0, len(lastSeen))
+	for id, t := range lastSeen {
+		ids = append(ids, id)
+		seen = append(seen, float64(t.UnixMilli())/1000)
+	}
+
+	if _, err := db.Exec(modelslogin.MyQueryTouchSessions, pq.Array(ids), pq.Array(seen)); err != nil {
+		return fmt.Errorf("error updating last activity: %v", err)
+	}
+	return nil
+}
+
+// IdleSessions returns the active sessions idle for at least minIdle.
+func IdleSessions(minIdle time.Duration) ([]IdleSession, error) {
+	db := meivanDB
+
+	rows, err := db.Query(modelslogin.MyQueryIdleSessions, minIdle.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error querying idle sessions: %v", err)
+	}
+	defer rows.Close()
+
+	var idle []IdleSession
+	for rows.Next() {
+		var s IdleSession
+		var username sql.NullString
+		var seconds float64
+		if err := rows.Scan(&s.SessionId, &username, &seconds); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		s.Username = username.String
+		s.Idle = time.Duration(seconds * float64(time.Second))
+		idle = append(idle, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading idle sessions: %v", err)
+	}
+	return idle, nil
+}
@@ -8,11 +8,10 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 25-08-2025
+// Last Modified Date: 17-10-2026
 package databaselogin
 
 import (
-	credentials "Hrmodule/dbconfig"
 	modelslogin "Hrmodule/models/login"
 	"database/sql"
 	"encoding/json"
@@ -27,17 +26,25 @@ type SessionDataRequest struct {
 	SessionID *string `json:"Session_id"`
 }
 
-// SessionDatadatabase executes query and returns SessionData list
-func SessionDatadatabase(w http.ResponseWriter, r *http.Request) ([]modelslogin.SessionDataStructure, int, error) {
-	// Connection string
-	connectionString := credentials.Getdatabasemeivan()
+// SessionIsActive reports whether the session_data row for sessionId is still active.
+// A session that does not exist is reported as inactive.
+func SessionIsActive(sessionId string) (bool, error) {
+	db := meivanDB
 
-	db, err := sql.Open("postgres", connectionString)
+	var isActive int
+	err := db.QueryRow(modelslogin.MyQuerySessionActive, sessionId).Scan(&isActive)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("DB open error: %v", err), http.StatusInternalServerError)
-		return nil, 0, err
+		return false, fmt.Errorf("error querying database: %v", err)
 	}
-	defer db.Close()
+	return isActive == 1, nil
+}
+
+// SessionDatadatabase executes query and returns SessionData list
+func SessionDatadatabase(w http.ResponseWriter, r *http.Request) ([]modelslogin.SessionDataStructure, int, error) {
+	db := meivanDB
 
 	// Decode POST body
 	var req SessionDataRequest
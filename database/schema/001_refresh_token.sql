@@ -0,0 +1,14 @@
+-- Refresh tokens issued by /HRldap and rotated by /TokenRefresh.
+-- Only the SHA-256 hash of the token is stored; the session_id ties every
+-- token of a family to its session_data row.
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id          BIGSERIAL PRIMARY KEY,
+    token_hash  CHAR(64)    NOT NULL UNIQUE,
+    session_id  VARCHAR(64) NOT NULL,
+    issued_on   TIMESTAMP   NOT NULL DEFAULT NOW(),
+    expires_on  TIMESTAMP   NOT NULL,
+    used_on     TIMESTAMP,
+    revoked_on  TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_session ON refresh_token (session_id);
@@ -0,0 +1,3 @@
+-- OTPs are generated by the server; only a salted SHA-256 hash is stored.
+ALTER TABLE otp_details ADD COLUMN IF NOT EXISTS otp_hash VARCHAR(100);
+ALTER TABLE otp_details ALTER COLUMN otp DROP NOT NULL;
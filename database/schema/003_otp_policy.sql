@@ -0,0 +1,11 @@
+-- Wrong guesses per OTP, and the per-username / per-mobile lockout used by the OTP policy.
+ALTER TABLE otp_details ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS otp_lockout (
+    subject_type  VARCHAR(16)  NOT NULL, -- 'username' or 'mobileno'
+    subject       VARCHAR(100) NOT NULL,
+    failures      INT          NOT NULL DEFAULT 0,
+    window_start  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    locked_until  TIMESTAMP,
+    PRIMARY KEY (subject_type, subject)
+);
@@ -0,0 +1,5 @@
+-- Delivery outcome of each OTP as reported by the configured sender.
+ALTER TABLE otp_details ADD COLUMN IF NOT EXISTS delivery_status   VARCHAR(16);  -- 'sent' or 'failed'
+ALTER TABLE otp_details ADD COLUMN IF NOT EXISTS delivery_attempts INT;
+ALTER TABLE otp_details ADD COLUMN IF NOT EXISTS delivery_ref      VARCHAR(255); -- provider message id or error
+ALTER TABLE otp_details ADD COLUMN IF NOT EXISTS delivered_on      TIMESTAMP;
@@ -0,0 +1,15 @@
+-- TOTP (authenticator app) enrolment; secret_enc is AES-GCM encrypted with utils.Encrypt.
+CREATE TABLE IF NOT EXISTS employee_totp (
+    employee_id  VARCHAR(50) PRIMARY KEY,
+    secret_enc   TEXT        NOT NULL,
+    created_on   TIMESTAMP   NOT NULL DEFAULT NOW(),
+    confirmed_on TIMESTAMP,                 -- NULL until the first code is confirmed
+    last_counter BIGINT      NOT NULL DEFAULT 0 -- last accepted time step, refuses replays
+);
+
+-- Second factor each employee is asked for at login.
+CREATE TABLE IF NOT EXISTS employee_mfa_preference (
+    employee_id      VARCHAR(50) PRIMARY KEY,
+    preferred_factor VARCHAR(8)  NOT NULL DEFAULT 'sms' CHECK (preferred_factor IN ('sms', 'totp')),
+    updated_on       TIMESTAMP   NOT NULL DEFAULT NOW()
+);
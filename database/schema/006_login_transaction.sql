@@ -0,0 +1,16 @@
+-- Two-phase login: /HRldap creates a row, the second factor completes it.
+-- state: ldap_passed -> otp_sent -> otp_verified -> session_active.
+-- The id becomes Session_Id / User_id in session_data once the login completes.
+CREATE TABLE IF NOT EXISTS login_transaction (
+    id          VARCHAR(64) PRIMARY KEY,
+    username    VARCHAR(100) NOT NULL,
+    employee_id VARCHAR(50)  NOT NULL,
+    department  VARCHAR(50)  NOT NULL,  -- LDAP OU the user bound in
+    state       VARCHAR(20)  NOT NULL
+        CHECK (state IN ('ldap_passed', 'otp_sent', 'otp_verified', 'session_active')),
+    created_on  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_on  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    expires_on  TIMESTAMP    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_transaction_expires ON login_transaction (expires_on);
@@ -0,0 +1,16 @@
+-- Roles provisioned from directory attributes at login (ROLE_MAPPING).
+-- ProvisionSource is NULL for mappings entered by HR; only rows with a
+-- source are ever deactivated by the login provisioning.
+ALTER TABLE ORGUNITUSERMAPPING ADD COLUMN IF NOT EXISTS ProvisionSource VARCHAR(16);
+
+CREATE TABLE IF NOT EXISTS role_provision_audit (
+    id         BIGSERIAL PRIMARY KEY,
+    username   VARCHAR(100) NOT NULL,
+    role_name  VARCHAR(100) NOT NULL,
+    action     VARCHAR(16)  NOT NULL,  -- granted, reactivated, revoked, unknown_role
+    source     VARCHAR(16)  NOT NULL,
+    detail     TEXT,
+    created_on TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_provision_audit_user ON role_provision_audit (username, created_on);
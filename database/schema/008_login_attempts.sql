@@ -0,0 +1,10 @@
+-- Failed /HRldap logins per username and per client IP, with the current block.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    subject_type  VARCHAR(16)  NOT NULL, -- 'username' or 'ip'
+    subject       VARCHAR(100) NOT NULL,
+    failures      INT          NOT NULL DEFAULT 0,
+    last_failure  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    blocked_until TIMESTAMP,             -- backoff or lockout end
+    locked        BOOLEAN      NOT NULL DEFAULT FALSE, -- blocked_until is a lockout, not a backoff
+    PRIMARY KEY (subject_type, subject)
+);
@@ -0,0 +1,8 @@
+-- Why a session ended: logout, idle_timeout, superseded (evicted by the
+-- concurrent-session policy) or token_reuse. NULL for older rows.
+ALTER TABLE session_data ADD COLUMN IF NOT EXISTS logout_reason VARCHAR(32);
+
+-- Active sessions per employee, read by the session policy at every login.
+CREATE INDEX IF NOT EXISTS idx_session_data_employee_active
+    ON session_data (employee_id, login_date)
+    WHERE is_active = '1';
@@ -0,0 +1,4 @@
+-- Device a session was opened from, shown by /MySessions.
+ALTER TABLE session_data ADD COLUMN IF NOT EXISTS user_agent   VARCHAR(512);
+ALTER TABLE session_data ADD COLUMN IF NOT EXISTS client_ip    VARCHAR(64);
+ALTER TABLE session_data ADD COLUMN IF NOT EXISTS device_label VARCHAR(64); -- X-Device-Label or derived from the user agent
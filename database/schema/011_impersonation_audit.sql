@@ -0,0 +1,20 @@
+-- Every request made with an impersonation ("act as employee") token, and
+-- every token issued by /Impersonate.
+CREATE TABLE IF NOT EXISTS impersonation_audit (
+    id                 BIGSERIAL    PRIMARY KEY,
+    actor_username     VARCHAR(100) NOT NULL, -- admin acting as the target
+    actor_employee_id  VARCHAR(50),
+    session_id         VARCHAR(100) NOT NULL, -- the admin's session
+    target_username    VARCHAR(100) NOT NULL,
+    target_employee_id VARCHAR(50),
+    method             VARCHAR(10)  NOT NULL,
+    route              VARCHAR(200) NOT NULL,
+    allowed            BOOLEAN      NOT NULL, -- false when the route refused the token
+    status             INT,                   -- HTTP status, NULL until the request completes
+    client_ip          VARCHAR(64),
+    created_on         TIMESTAMP    NOT NULL DEFAULT NOW(),
+    completed_on       TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonation_audit_actor  ON impersonation_audit (actor_username, created_on);
+CREATE INDEX IF NOT EXISTS idx_impersonation_audit_target ON impersonation_audit (target_username, created_on);
@@ -0,0 +1,8 @@
+-- Last request seen on a session, written in batches by the JWT middleware.
+-- NULL until the first flush; the reaper then counts idle time from login_date.
+ALTER TABLE session_data ADD COLUMN IF NOT EXISTS last_activity TIMESTAMP;
+
+-- Active sessions scanned by the idle-session reaper.
+CREATE INDEX IF NOT EXISTS idx_session_data_active_activity
+    ON session_data (COALESCE(last_activity, login_date))
+    WHERE is_active = 1;
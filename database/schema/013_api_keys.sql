@@ -0,0 +1,64 @@
+-- Native API-key validation, replacing the API_Validation_New procedure.
+-- Runs on the API validation (MySQL) database, next to Client_Request.
+--
+-- Keys are stored as the hex SHA-256 of the key; the key itself is shown
+-- once when issued. Keys handed out by the old procedure can be carried
+-- over with INSERT INTO api_key (vendor_id, key_hash, key_prefix, created_by)
+-- SELECT ..., SHA2(<plain key>, 256), LEFT(<plain key>, 8), 'migration'.
+
+-- A consumer of the API (e.g. the HR portal front end, a payroll vendor)
+CREATE TABLE IF NOT EXISTS api_vendor (
+    vendor_id    INT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    vendor_name  VARCHAR(100) NOT NULL UNIQUE,
+    is_active    TINYINT(1)   NOT NULL DEFAULT 1,
+    active_from  DATETIME     NULL,     -- NULL: no start date
+    active_until DATETIME     NULL,     -- NULL: no expiry
+    created_on   DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- An API, named by the first path segment (/TaskInbox -> TaskInbox)
+CREATE TABLE IF NOT EXISTS api_master (
+    api_name     VARCHAR(100) NOT NULL PRIMARY KEY,
+    is_active    TINYINT(1)   NOT NULL DEFAULT 1,
+    active_from  DATETIME     NULL,
+    active_until DATETIME     NULL
+);
+
+-- The APIs a vendor may call, each with its own activation window
+CREATE TABLE IF NOT EXISTS api_vendor_api (
+    vendor_id    INT          NOT NULL,
+    api_name     VARCHAR(100) NOT NULL,
+    is_active    TINYINT(1)   NOT NULL DEFAULT 1,
+    active_from  DATETIME     NULL,
+    active_until DATETIME     NULL,
+    PRIMARY KEY (vendor_id, api_name),
+    FOREIGN KEY (vendor_id) REFERENCES api_vendor (vendor_id),
+    FOREIGN KEY (api_name)  REFERENCES api_master (api_name)
+);
+
+-- Addresses a vendor may call from: a single IP (10.0.0.5) or a CIDR
+-- range (10.0.0.0/24, 2001:db8::/32). A vendor without entries is refused.
+CREATE TABLE IF NOT EXISTS api_vendor_ip (
+    vendor_id INT         NOT NULL,
+    cidr      VARCHAR(64) NOT NULL,
+    is_active TINYINT(1)  NOT NULL DEFAULT 1,
+    PRIMARY KEY (vendor_id, cidr),
+    FOREIGN KEY (vendor_id) REFERENCES api_vendor (vendor_id)
+);
+
+CREATE TABLE IF NOT EXISTS api_key (
+    key_id      BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    vendor_id   INT          NOT NULL,
+    key_hash    CHAR(64)     NOT NULL UNIQUE, -- hex SHA-256 of the key
+    key_prefix  CHAR(8)      NOT NULL,        -- first characters, to recognise a key in logs
+    is_active   TINYINT(1)   NOT NULL DEFAULT 1,
+    created_by  VARCHAR(100) NOT NULL,
+    created_on  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_on  DATETIME     NULL,            -- NULL: no expiry
+    revoked_by  VARCHAR(100) NULL,
+    revoked_on  DATETIME     NULL,
+    replaced_by BIGINT       NULL,            -- key issued when this one was rotated
+    FOREIGN KEY (vendor_id) REFERENCES api_vendor (vendor_id)
+);
+
+CREATE INDEX idx_api_key_vendor ON api_key (vendor_id, is_active);
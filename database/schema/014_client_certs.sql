@@ -0,0 +1,23 @@
+-- Client certificates of internal campus services that call the API over
+-- the mutual-TLS listener (MTLS_PORT) instead of sending an API key.
+--
+-- A certificate must chain to MTLS_CLIENT_CA_FILE and be registered here;
+-- it then acts for its vendor with the vendor's API grants and IP
+-- allowlist (see 013_api_keys.sql). The fingerprint is the hex SHA-256 of
+-- the DER certificate:
+--   openssl x509 -in client.pem -outform DER | sha256sum
+-- Register it with
+--   INSERT INTO api_vendor_cert (vendor_id, fingerprint, subject, created_by)
+--   VALUES (<vendor_id>, '<fingerprint>', '<subject>', '<admin>');
+
+CREATE TABLE IF NOT EXISTS api_vendor_cert (
+    cert_id     BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    vendor_id   INT          NOT NULL,
+    fingerprint CHAR(64)     NOT NULL UNIQUE, -- hex SHA-256 of the DER certificate
+    subject     VARCHAR(255) NOT NULL,        -- for people reading the table
+    is_active   TINYINT(1)   NOT NULL DEFAULT 1,
+    created_by  VARCHAR(100) NOT NULL,
+    created_on  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_on  DATETIME     NULL,            -- NULL: until the certificate itself expires
+    FOREIGN KEY (vendor_id) REFERENCES api_vendor (vendor_id)
+);
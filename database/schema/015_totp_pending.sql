@@ -0,0 +1,4 @@
+-- A replacement TOTP secret waiting for /TotpConfirm; the confirmed secret_enc
+-- stays in use for login until the replacement is confirmed.
+ALTER TABLE employee_totp ADD COLUMN IF NOT EXISTS pending_secret_enc TEXT;
+ALTER TABLE employee_totp ADD COLUMN IF NOT EXISTS pending_created_on TIMESTAMP;
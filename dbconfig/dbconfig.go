@@ -1,54 +1,28 @@
-
 package credentials
 
 import (
-	"database/sql"
+	"Hrmodule/config"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
-	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
 
-func init() {
-	err := godotenv.Load(".env")
-	if err != nil {
-		panic("Failed to load .env: " + err.Error())
-	}
-}
-
-// getDBConnectionString constructs and verifies a database connection string
-// for the given driver (e.g., "postgres" or "mysql"). It opens and pings the
-// database to ensure the connection is valid. It returns the connection string
-// or panics on failure.
-func getDBConnectionString(driver, server, user, password, database, port string) string {
-	var connStr string
-
+// connectionString builds the connection string for the given driver
+// ("postgres" or "mysql"). It panics on an unsupported driver.
+func connectionString(driver, server, user, password, database, port string) string {
 	switch driver {
 	case "postgres":
 		// ✅ Correct DSN format for lib/pq and gorm postgres driver
-		connStr = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+		return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 			server, user, password, database, port)
 	case "mysql":
-		connStr = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", user, password, server, port, database)
+		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", user, password, server, port, database)
 	default:
 		panic("Unsupported DB driver: " + driver)
 	}
-
-	db, err := sql.Open(driver, connStr)
-	if err != nil {
-		panic("Failed to open DB connection: " + err.Error())
-	}
-	defer db.Close()
-
-	if err := db.Ping(); err != nil {
-		panic("Database connection failed: " + err.Error())
-	}
-
-	return connStr
 }
 
 // logMaskedConnection masks password and logs the connection string
@@ -70,75 +44,21 @@ func logFullyMaskedConnection(connStr, password, user, host, database, dbType st
 	log.Printf("%s connection: %s", dbType, safeConnStr)
 }
 
-// Postgres database
-// Getdatabasehr returns Postgres connection string
-func Getdatabasehr() string {
-	serverhr := os.Getenv("serverhr")
-	userhr := os.Getenv("userIdhr")
-	passwordhr := os.Getenv("passwordhr")
-	databasehr := os.Getenv("databasehr")
-	porthr := os.Getenv("porthr")
-
-	connStr := getDBConnectionString("postgres", serverhr, userhr, passwordhr, databasehr, porthr)
-
-	// Choose one:
-	// logMaskedConnection(connStr, password, "Postgres")  // Only mask password
-	logFullyMaskedConnection(connStr, passwordhr, userhr, serverhr, databasehr, "Postgres") // Mask all sensitive info
-
-	return connStr
-}
-
-// Getdatabasehr returns Postgres connection string
-func Getdatabasemeivan() string {
-	serverm := os.Getenv("serverm")
-	userm := os.Getenv("userIdm")
-	passwordm := os.Getenv("passwordm")
-	databasem := os.Getenv("databasem")
-	portm := os.Getenv("portm")
-	log.Println(serverm)
-	log.Println(userm)
-	log.Println(passwordm)
-	log.Println(databasem)
-	log.Println(portm)
-	connStr := getDBConnectionString("postgres", serverm, userm, passwordm, databasem, portm)
-
-	// Choose one:
-	// logMaskedConnection(connStr, password, "Postgres")  // Only mask password
-	logFullyMaskedConnection(connStr, passwordm, userm, serverm, databasem, "Postgres") // Mask all sensitive info
-
-	return connStr
-}
-
-//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-
-// GetMySQLDatabase17 returns MySQL connection string
-func GetMySQLDatabase17() string {
-	host := os.Getenv("DB_HOST")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	database := os.Getenv("DB_NAME")
-	port := os.Getenv("DB_PORT")
-	log.Println(host)
-	log.Println(user)
-	log.Println(password)
-	log.Println(database)
-	log.Println(port)
-	connStr := getDBConnectionString("mysql", host, user, password, database, port)
-	logFullyMaskedConnection(connStr, password, user, host, database, "MySQL")
-
-	return connStr
-}
-
-// GetMySQLDatabase17HR returns MySQL HR connection string
-func GetMySQLDatabase17HR() string {
-	host := os.Getenv("DB_HOST_HR")
-	user := os.Getenv("DB_USER_HR")
-	password := os.Getenv("DB_PASSWORD_HR")
-	database := os.Getenv("DB_NAME_HR")
-	port := os.Getenv("DB_PORT_HR")
-
-	connStr := getDBConnectionString("mysql", host, user, password, database, port)
-	logFullyMaskedConnection(connStr, password, user, host, database, "MySQL HR")
-
-	return connStr
+// databases lists the logical databases with their credentials and pool
+// limits. Each gets one pool, see OpenPools.
+func databases(c config.Databases) []databaseSpec {
+	return []databaseSpec{
+		// Postgres HR database
+		{Name: PoolHR, Driver: "postgres", Server: c.HRServer, User: c.HRUser, Password: c.HRPassword,
+			Database: c.HRName, Port: c.HRPort, Pool: c.Pool.With(c.HRPool)},
+		// Postgres Meivan database (sessions, roles, workflow)
+		{Name: PoolMeivan, Driver: "postgres", Server: c.MeivanServer, User: c.MeivanUser, Password: c.MeivanPassword,
+			Database: c.MeivanName, Port: c.MeivanPort, Pool: c.Pool.With(c.MeivanPool)},
+		// 17 Server MySQL API validation database
+		{Name: PoolAPIValidation, Driver: "mysql", Server: c.APIValidationServer, User: c.APIValidationUser, Password: c.APIValidationPassword,
+			Database: c.APIValidationName, Port: c.APIValidationPort, Pool: c.Pool.With(c.APIValidationPool)},
+		// 17 Server MySQL Hrmodule database
+		{Name: PoolHRMySQL, Driver: "mysql", Server: c.HRMySQLServer, User: c.HRMySQLUser, Password: c.HRMySQLPassword,
+			Database: c.HRMySQLName, Port: c.HRMySQLPort, Pool: c.Pool.With(c.HRMySQLPool)},
+	}
 }
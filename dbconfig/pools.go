@@ -0,0 +1,130 @@
+// Package credentials opens the long-lived connection pools of the
+// application's databases.
+//
+// OpenPools is called once at startup and opens one *sql.DB per logical
+// database whose server variable is set. The pools are handed to the
+// packages that query them (see UsePools in database/* and controllers/*),
+// so a request borrows an already authenticated connection instead of
+// opening, pinging and closing its own.
+//
+// Pool limits come from DB_POOL_MAX_OPEN, DB_POOL_MAX_IDLE,
+// DB_POOL_CONN_MAX_LIFETIME and DB_POOL_CONN_MAX_IDLE_TIME and can be set
+// per pool by inserting the pool name, e.g. DB_POOL_MEIVAN_MAX_OPEN or
+// DB_POOL_API_VALIDATION_CONN_MAX_LIFETIME (see config.Databases).
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package credentials
+
+import (
+	"Hrmodule/config"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// Names of the logical databases.
+const (
+	PoolHR            = "hr"             // Postgres HR database (employee master)
+	PoolMeivan        = "meivan"         // Postgres Meivan database (sessions, roles, workflow)
+	PoolAPIValidation = "api-validation" // MySQL API keys, vendors and Client_Request
+	PoolHRMySQL       = "hr-mysql"       // MySQL Hrmodule database
+)
+
+// databaseSpec holds a database's credentials and pool limits.
+type databaseSpec struct {
+	Name     string
+	Driver   string
+	Server   string
+	User     string
+	Password string
+	Database string
+	Port     string
+	Pool     config.Pool
+}
+
+// Pools is the registry of open database pools.
+type Pools struct {
+	dbs map[string]*sql.DB
+}
+
+// OpenPools opens and pings a pool for every database of cfg whose server
+// is set. On error every pool opened so far is closed again.
+func OpenPools(cfg *config.Config) (*Pools, error) {
+	p := &Pools{dbs: make(map[string]*sql.DB)}
+	for _, spec := range databases(cfg.Databases) {
+		if spec.Server == "" {
+			log.Printf("Database %s not configured (no server set), skipping", spec.Name)
+			continue
+		}
+
+		server, user, password, database := spec.Server, spec.User, spec.Password, spec.Database
+		connStr := connectionString(spec.Driver, server, user, password, database, spec.Port)
+
+		db, err := sql.Open(spec.Driver, connStr)
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("open %s: %v", spec.Name, err)
+		}
+		db.SetMaxOpenConns(spec.Pool.MaxOpen)
+		db.SetMaxIdleConns(spec.Pool.MaxIdle)
+		db.SetConnMaxLifetime(spec.Pool.ConnMaxLifetime)
+		db.SetConnMaxIdleTime(spec.Pool.ConnMaxIdleTime)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = db.PingContext(ctx)
+		cancel()
+		if err != nil {
+			db.Close()
+			p.Close()
+			return nil, fmt.Errorf("ping %s: %v", spec.Name, err)
+		}
+
+		logFullyMaskedConnection(connStr, password, user, server, database, spec.Name)
+		p.dbs[spec.Name] = db
+	}
+	return p, nil
+}
+
+// DB returns the pool of the named database. It panics if the database is
+// not configured, so a missing pool is found when UsePools runs at startup
+// rather than on the first request.
+func (p *Pools) DB(name string) *sql.DB {
+	db, ok := p.dbs[name]
+	if !ok {
+		panic("Database pool " + name + " is not configured")
+	}
+	return db
+}
+
+// Names returns the names of the open pools, sorted.
+func (p *Pools) Names() []string {
+	names := make([]string, 0, len(p.dbs))
+	for name := range p.dbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close closes every pool.
+func (p *Pools) Close() error {
+	var errs []error
+	for name, db := range p.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %v", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,205 @@
+// Package directory provides the Authenticator used by /HRldap to check a
+// username and password against the institute directory.
+//
+// The provider is chosen with DIRECTORY_PROVIDER:
+//   - ldap (default): LDAPAuthenticator configured from the LDAP_* settings
+//   - memory: MemoryDirectory, for tests and local development
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package directory
+
+import (
+	"Hrmodule/config"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// UserType is the name of the OU an entry was found in. It is stored as
+// Department in session_data.
+type UserType string
+
+// User types of the institute directory, in the default search order.
+const (
+	UserTypeStaff   UserType = "staff"
+	UserTypeFaculty UserType = "faculty"
+	UserTypeProject UserType = "project"
+	UserTypeStudent UserType = "student"
+)
+
+// Identity is the directory entry of an authenticated user.
+type Identity struct {
+	Username     string              // login name the user authenticated with
+	DN           string              // distinguished name of the entry
+	UserType     UserType            // OU the entry was found in
+	DisplayName  string              // from the displayName attribute mapping
+	Mail         string              // from the mail attribute mapping
+	EmployeeType string              // from the employeeType attribute mapping
+	MemberOf     []string            // group DNs from the memberOf attribute mapping
+	Attributes   map[string][]string // every attribute that was requested, by LDAP name
+}
+
+// Authenticator checks a username and password against a directory.
+type Authenticator interface {
+	// Authenticate returns the identity of the user or one of the errors
+	// below. Errors matching ErrInvalidCredentials must be answered the
+	// same way, so a client cannot tell unknown users from wrong passwords.
+	Authenticate(ctx context.Context, username, password string) (*Identity, error)
+}
+
+// Pinger is implemented by directories that can check their servers are
+// reachable without a user's credentials.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Errors returned by Authenticate.
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidUsername    = fmt.Errorf("%w: malformed username", ErrInvalidCredentials)
+	ErrUserNotFound       = fmt.Errorf("%w: no matching entry", ErrInvalidCredentials)
+	ErrWrongPassword      = fmt.Errorf("%w: bind rejected", ErrInvalidCredentials)
+	ErrAmbiguousUser      = errors.New("more than one directory entry matches the username")
+)
+
+// maxUsernameLength bounds the login name accepted by validUsername.
+const maxUsernameLength = 64
+
+// validUsername rejects empty, overlong and control-character usernames
+// before they reach the directory.
+func validUsername(username string) bool {
+	if username == "" || len(username) > maxUsernameLength {
+		return false
+	}
+	for _, r := range username {
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// OU is one search base, tried in the configured order.
+type OU struct {
+	Name   UserType // user type of the entries found under BaseDN
+	BaseDN string
+}
+
+// AttributeMap names the LDAP attributes read into Identity.
+type AttributeMap struct {
+	DisplayName  string
+	Mail         string
+	EmployeeType string
+	MemberOf     string
+}
+
+// DefaultAttributeMap is the attribute mapping of the institute directory.
+var DefaultAttributeMap = AttributeMap{
+	DisplayName:  "displayName",
+	Mail:         "mail",
+	EmployeeType: "employeeType",
+	MemberOf:     "memberOf",
+}
+
+// names returns the non-empty LDAP attribute names of the mapping.
+func (m AttributeMap) names() []string {
+	var out []string
+	for _, name := range []string{m.DisplayName, m.Mail, m.EmployeeType, m.MemberOf} {
+		if name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// fill copies the mapped attributes into id.
+func (m AttributeMap) fill(id *Identity) {
+	first := func(name string) string {
+		if values := id.Attributes[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	id.DisplayName = first(m.DisplayName)
+	id.Mail = first(m.Mail)
+	id.EmployeeType = first(m.EmployeeType)
+	if m.MemberOf != "" {
+		id.MemberOf = id.Attributes[m.MemberOf]
+	}
+}
+
+// FromConfig returns the Authenticator selected by DIRECTORY_PROVIDER.
+func FromConfig(c config.Directory) (Authenticator, error) {
+	switch provider := strings.ToLower(c.Provider); provider {
+	case "ldap":
+		cfg, err := LDAPConfigFrom(c.LDAP)
+		if err != nil {
+			return nil, err
+		}
+		return NewLDAPAuthenticator(cfg)
+	case "memory":
+		return MemoryDirectoryFrom(c.MemoryUsers)
+	default:
+		return nil, fmt.Errorf("unknown DIRECTORY_PROVIDER %q", provider)
+	}
+}
+
+// parseOUs parses "name=baseDN;name=baseDN". The first "=" separates the
+// name, so base DNs may contain "=" and ",".
+func parseOUs(v string) ([]OU, error) {
+	var ous []OU
+	for _, part := range strings.Split(v, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, base, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(name) == "" || strings.TrimSpace(base) == "" {
+			return nil, fmt.Errorf("invalid OU entry %q, want name=baseDN", part)
+		}
+		ous = append(ous, OU{Name: UserType(strings.TrimSpace(name)), BaseDN: strings.TrimSpace(base)})
+	}
+	if len(ous) == 0 {
+		return nil, errors.New("no OUs configured")
+	}
+	return ous, nil
+}
+
+// parseAttributeMap overrides DefaultAttributeMap with "field=attribute,..."
+// where field is displayName, mail, employeeType or memberOf.
+func parseAttributeMap(v string) (AttributeMap, error) {
+	m := DefaultAttributeMap
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, attr, ok := strings.Cut(part, "=")
+		if !ok {
+			return m, fmt.Errorf("invalid attribute mapping %q, want field=attribute", part)
+		}
+		attr = strings.TrimSpace(attr)
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "displayname":
+			m.DisplayName = attr
+		case "mail":
+			m.Mail = attr
+		case "employeetype":
+			m.EmployeeType = attr
+		case "memberof":
+			m.MemberOf = attr
+		default:
+			return m, fmt.Errorf("unknown attribute mapping field %q", field)
+		}
+	}
+	return m, nil
+}
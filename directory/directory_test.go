@@ -0,0 +1,39 @@
+package directory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOUs(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []OU
+		wantErr bool
+	}{
+		{"single OU", "staff=ou=staff,dc=example,dc=edu", []OU{{"staff", "ou=staff,dc=example,dc=edu"}}, false},
+		{
+			"order kept and spaces trimmed", " staff = ou=staff,dc=example ; student=ou=students,dc=example ",
+			[]OU{{"staff", "ou=staff,dc=example"}, {"student", "ou=students,dc=example"}}, false,
+		},
+		{"empty entries skipped", "staff=ou=staff;;", []OU{{"staff", "ou=staff"}}, false},
+		{"no =", "staff", nil, true},
+		{"empty name", "=ou=staff", nil, true},
+		{"empty base DN", "staff= ", nil, true},
+		{"one bad entry", "staff=ou=staff;student", nil, true},
+		{"nothing configured", "", nil, true},
+		{"only separators", " ; ", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOUs(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOUs(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseOUs(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Package directory provides LDAPAuthenticator, the Authenticator for the
+// institute LDAP server.
+//
+// The service account binds first and searches the configured OUs in
+// order with the escaped username; the first OU with a match decides, and
+// exactly one entry must match there.
+// Connections come from a pool (see pool.go); when a server fails mid-way
+// the request is retried once on each of the other servers.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package directory
+
+import (
+	"Hrmodule/config"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	ldap "github.com/go-ldap/ldap/v3"
+)
+
+// LDAPConfig holds the connection and search settings of LDAPAuthenticator.
+type LDAPConfig struct {
+	URLs         []string     // tried in order until one connects
+	BindDN       string       // service account used for searching
+	BindPassword string       // password of the service account
+	OUs          []OU         // search bases, in the order they are tried
+	UserFilter   string       // search filter; {username} is replaced by the login name
+	Attributes   AttributeMap // attributes read into Identity
+
+	TLSMode          string        // TLSModeStartTLS, TLSModeLDAPS or TLSModeNone
+	CAFile           string        // PEM bundle to verify the servers; system roots if empty
+	PoolSize         int           // connections in use at once, and kept idle
+	DialTimeout      time.Duration // TCP connect (and ldaps handshake) timeout
+	RequestTimeout   time.Duration // timeout of each bind and search
+	HealthCheckAfter time.Duration // idle time after which a connection is checked before reuse
+	BreakerThreshold int           // consecutive failures that open a server's breaker
+	BreakerCooldown  time.Duration // how long an open breaker skips the server
+}
+
+// DefaultUserFilter matches the entry by its uid.
+const DefaultUserFilter = "(&(objectclass=*)(uid={username}))"
+
+// LDAPConfigFrom builds LDAPConfig from the LDAP_* settings. config.Load
+// has checked the required values; the OU and attribute lists are parsed here.
+func LDAPConfigFrom(c config.LDAP) (LDAPConfig, error) {
+	cfg := LDAPConfig{
+		URLs:             c.URLs,
+		BindDN:           c.BindDN,
+		BindPassword:     c.BindPassword,
+		UserFilter:       c.UserFilter,
+		TLSMode:          strings.ToLower(c.TLSMode),
+		CAFile:           c.CAFile,
+		PoolSize:         c.PoolSize,
+		DialTimeout:      c.DialTimeout,
+		RequestTimeout:   c.RequestTimeout,
+		HealthCheckAfter: c.HealthCheckAfter,
+		BreakerThreshold: c.BreakerThreshold,
+		BreakerCooldown:  c.BreakerCooldown,
+	}
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = DefaultUserFilter
+	}
+
+	var err error
+	if cfg.OUs, err = parseOUs(c.OUs); err != nil {
+		return cfg, fmt.Errorf("LDAP_OUS: %v", err)
+	}
+	if cfg.Attributes, err = parseAttributeMap(c.Attributes); err != nil {
+		return cfg, fmt.Errorf("LDAP_ATTRIBUTES: %v", err)
+	}
+	return cfg, nil
+}
+
+// LDAPAuthenticator authenticates against an LDAP server.
+type LDAPAuthenticator struct {
+	cfg  LDAPConfig
+	pool *connPool
+}
+
+// NewLDAPAuthenticator returns an LDAPAuthenticator for cfg. Connections
+// are opened on first use.
+func NewLDAPAuthenticator(cfg LDAPConfig) (*LDAPAuthenticator, error) {
+	a := &LDAPAuthenticator{cfg: cfg}
+	pool, err := newConnPool(&a.cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.pool = pool
+	return a, nil
+}
+
+// isNetworkError reports whether err means the connection itself failed.
+func isNetworkError(err error) bool {
+	return ldap.IsErrorWithCode(err, ldap.ErrorNetwork) || ldap.IsErrorWithCode(err, ldap.ErrorUnexpectedMessage)
+}
+
+// Authenticate implements Authenticator. If a server fails during the
+// request, the request is repeated on the next server.
+func (a *LDAPAuthenticator) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	if !validUsername(username) {
+		return nil, ErrInvalidUsername
+	}
+	if password == "" {
+		// An empty password would be an unauthenticated bind that succeeds
+		return nil, ErrWrongPassword
+	}
+
+	failed := make(map[*server]bool)
+	for {
+		pc, err := a.pool.get(ctx, failed)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := a.authenticate(ctx, pc, username, password)
+		if err != nil && isNetworkError(err) {
+			log.Printf("LDAP server %s failed during login: %v", pc.server.url, err)
+			failed[pc.server] = true
+			a.pool.fail(pc)
+			continue
+		}
+		return id, err
+	}
+}
+
+// Ping implements Pinger: it reads the root DSE over a service-bound
+// connection from the pool.
+func (a *LDAPAuthenticator) Ping(ctx context.Context) error {
+	pc, err := a.pool.get(ctx, nil)
+	if err != nil {
+		return err
+	}
+	req := ldap.NewSearchRequest("", ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1,
+		int(a.cfg.RequestTimeout.Seconds()), false, "(objectClass=*)", []string{"1.1"}, nil)
+	if _, err := pc.Search(req); err != nil {
+		if isNetworkError(err) {
+			a.pool.fail(pc)
+		} else {
+			a.pool.put(pc, false)
+		}
+		return fmt.Errorf("LDAP server %s: %v", pc.server.url, err)
+	}
+	a.pool.put(pc, true)
+	return nil
+}
+
+// authenticate finds the user's entry on pc and binds as it. The OUs are
+// searched in order and the first OU with a match decides: exactly one
+// entry must match, and only that entry's DN is tried. pc is returned to
+// the pool bound as the service account again, or closed.
+func (a *LDAPAuthenticator) authenticate(ctx context.Context, pc *pooledConn, username, password string) (id *Identity, err error) {
+	reusable := true
+	userBound := false
+	defer func() {
+		if err != nil && isNetworkError(err) {
+			return // the caller discards pc
+		}
+		if userBound {
+			// Restore the service identity before the connection is reused
+			if rebindErr := pc.Bind(a.cfg.BindDN, a.cfg.BindPassword); rebindErr != nil {
+				log.Printf("Service account rebind failed: %v", rebindErr)
+				reusable = false
+			}
+		}
+		a.pool.put(pc, reusable)
+	}()
+
+	filter := strings.ReplaceAll(a.cfg.UserFilter, "{username}", ldap.EscapeFilter(username))
+	attributes := a.cfg.Attributes.names()
+
+	for _, ou := range a.cfg.OUs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Size limit 2 is enough to detect an ambiguous match
+		req := ldap.NewSearchRequest(
+			ou.BaseDN,
+			ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(a.cfg.RequestTimeout.Seconds()), false,
+			filter,
+			attributes,
+			nil,
+		)
+
+		sr, err := pc.Search(req)
+		if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			if isNetworkError(err) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("search in %s failed: %w", ou.Name, err)
+		}
+		if sr == nil || len(sr.Entries) == 0 {
+			continue
+		}
+		if len(sr.Entries) > 1 {
+			log.Printf("%s: %d entries match %q", ou.Name, len(sr.Entries), username)
+			return nil, ErrAmbiguousUser
+		}
+
+		entry := sr.Entries[0]
+		userBound = true
+		if err := pc.Bind(entry.DN, password); err != nil {
+			if isNetworkError(err) {
+				return nil, err
+			}
+			if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+				log.Printf("%s Bind Failed for %s", ou.Name, entry.DN)
+				return nil, ErrWrongPassword
+			}
+			return nil, fmt.Errorf("bind as %s failed: %w", entry.DN, err)
+		}
+		log.Printf("%s Bind Successful", ou.Name)
+
+		id := &Identity{
+			Username:   username,
+			DN:         entry.DN,
+			UserType:   ou.Name,
+			Attributes: make(map[string][]string, len(entry.Attributes)),
+		}
+		for _, attr := range entry.Attributes {
+			id.Attributes[attr.Name] = attr.Values
+		}
+		a.cfg.Attributes.fill(id)
+		return id, nil
+	}
+
+	return nil, ErrUserNotFound
+}
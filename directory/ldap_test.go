@@ -0,0 +1,224 @@
+package directory
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	ldap "github.com/go-ldap/ldap/v3"
+)
+
+const (
+	testBindDN   = "cn=service,dc=example"
+	testBindPass = "service-secret"
+)
+
+var testOUs = []OU{
+	{"staff", "ou=staff,dc=example"},
+	{"student", "ou=students,dc=example"},
+}
+
+// fakeLDAP is an ldap.Client serving fixed entries. Search returns every
+// entry under the base DN whatever the filter, so the filters it records
+// show exactly what a real server would have been asked.
+type fakeLDAP struct {
+	ldap.Client // nil; methods the pool does not use panic
+
+	entries   map[string][]string // base DN -> entry DNs
+	passwords map[string]string   // DN -> password
+	filters   []string            // filters searched, in order
+	binds     []string            // DNs bound, in order
+	bound     string              // DN of the last successful bind
+}
+
+func (f *fakeLDAP) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	f.filters = append(f.filters, req.Filter)
+	sr := &ldap.SearchResult{}
+	for _, dn := range f.entries[req.BaseDN] {
+		if len(sr.Entries) == req.SizeLimit {
+			return sr, ldap.NewError(ldap.LDAPResultSizeLimitExceeded, errors.New("size limit exceeded"))
+		}
+		sr.Entries = append(sr.Entries, ldap.NewEntry(dn, map[string][]string{"mail": {dn}}))
+	}
+	return sr, nil
+}
+
+func (f *fakeLDAP) Bind(dn, password string) error {
+	f.binds = append(f.binds, dn)
+	if want, ok := f.passwords[dn]; !ok || want != password {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	f.bound = dn
+	return nil
+}
+
+func (f *fakeLDAP) IsClosing() bool { return false }
+func (f *fakeLDAP) Close() error    { return nil }
+
+// newFakeAuthenticator returns an LDAPAuthenticator whose pool holds one
+// service-bound connection to f.
+func newFakeAuthenticator(f *fakeLDAP) *LDAPAuthenticator {
+	if f.passwords == nil {
+		f.passwords = make(map[string]string)
+	}
+	f.passwords[testBindDN] = testBindPass
+	f.bound = testBindDN
+
+	a := &LDAPAuthenticator{cfg: LDAPConfig{
+		BindDN:           testBindDN,
+		BindPassword:     testBindPass,
+		OUs:              testOUs,
+		UserFilter:       DefaultUserFilter,
+		Attributes:       DefaultAttributeMap,
+		PoolSize:         1,
+		HealthCheckAfter: time.Hour,
+	}}
+	a.pool = &connPool{cfg: &a.cfg, slots: make(chan struct{}, 1)}
+	a.pool.idle = []*pooledConn{{
+		Client:   f,
+		server:   &server{url: "ldap://fake", breaker: &breaker{threshold: 1}},
+		lastUsed: time.Now(),
+	}}
+	return a
+}
+
+func TestLDAPAuthenticateEscapesUsername(t *testing.T) {
+	tests := []struct {
+		name       string
+		username   string
+		wantFilter string // searched in every OU; empty for no search
+		wantErr    error
+	}{
+		{"plain", "alice", "(&(objectclass=*)(uid=alice))", ErrUserNotFound},
+		{"wildcard", "*", `(&(objectclass=*)(uid=\2a))`, ErrUserNotFound},
+		{"filter injection", ")(uid=*", `(&(objectclass=*)(uid=\29\28uid=\2a))`, ErrUserNotFound},
+		{"always-true injection", "*)(|(objectclass=*", `(&(objectclass=*)(uid=\2a\29\28|\28objectclass=\2a))`, ErrUserNotFound},
+		{"backslash", `\`, `(&(objectclass=*)(uid=\5c))`, ErrUserNotFound},
+		{"NUL byte", "alice\x00", "", ErrInvalidUsername},
+		{"empty", "", "", ErrInvalidUsername},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeLDAP{}
+			a := newFakeAuthenticator(f)
+
+			_, err := a.Authenticate(context.Background(), tt.username, "password")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate(%q) error = %v, want %v", tt.username, err, tt.wantErr)
+			}
+			var want []string
+			if tt.wantFilter != "" {
+				want = []string{tt.wantFilter, tt.wantFilter}
+			}
+			if !reflect.DeepEqual(f.filters, want) {
+				t.Errorf("filters = %q, want %q", f.filters, want)
+			}
+		})
+	}
+}
+
+func TestLDAPAuthenticateMatches(t *testing.T) {
+	const (
+		staffDN   = "uid=alice,ou=staff,dc=example"
+		otherDN   = "uid=alice,ou=contract,ou=staff,dc=example"
+		thirdDN   = "uid=alice,ou=visiting,ou=staff,dc=example"
+		studentDN = "uid=alice,ou=students,dc=example"
+	)
+	tests := []struct {
+		name      string
+		entries   map[string][]string
+		passwords map[string]string
+		wantDN    string
+		wantType  UserType
+		wantErr   error
+		wantBinds []string // user DNs bound, the service rebinds left out
+	}{
+		{
+			name:      "single match",
+			entries:   map[string][]string{testOUs[0].BaseDN: {staffDN}},
+			passwords: map[string]string{staffDN: "secret"},
+			wantDN:    staffDN, wantType: "staff", wantBinds: []string{staffDN},
+		},
+		{
+			name:      "match in a later OU",
+			entries:   map[string][]string{testOUs[1].BaseDN: {studentDN}},
+			passwords: map[string]string{studentDN: "secret"},
+			wantDN:    studentDN, wantType: "student", wantBinds: []string{studentDN},
+		},
+		{
+			name:      "first OU with a match decides",
+			entries:   map[string][]string{testOUs[0].BaseDN: {staffDN}, testOUs[1].BaseDN: {studentDN}},
+			passwords: map[string]string{staffDN: "other", studentDN: "secret"},
+			wantErr:   ErrWrongPassword, wantBinds: []string{staffDN},
+		},
+		{
+			name:    "two entries in one OU",
+			entries: map[string][]string{testOUs[0].BaseDN: {staffDN, otherDN}},
+			wantErr: ErrAmbiguousUser,
+		},
+		{
+			name:    "size limit exceeded",
+			entries: map[string][]string{testOUs[0].BaseDN: {staffDN, otherDN, thirdDN}},
+			wantErr: ErrAmbiguousUser,
+		},
+		{
+			name:    "ambiguous OU stops the search",
+			entries: map[string][]string{testOUs[0].BaseDN: {staffDN, otherDN}, testOUs[1].BaseDN: {studentDN}},
+			wantErr: ErrAmbiguousUser,
+		},
+		{
+			name:      "wrong password",
+			entries:   map[string][]string{testOUs[0].BaseDN: {staffDN}},
+			passwords: map[string]string{staffDN: "other"},
+			wantErr:   ErrWrongPassword, wantBinds: []string{staffDN},
+		},
+		{
+			name:    "no match",
+			wantErr: ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeLDAP{entries: tt.entries, passwords: tt.passwords}
+			a := newFakeAuthenticator(f)
+
+			id, err := a.Authenticate(context.Background(), "alice", "secret")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (id.DN != tt.wantDN || id.UserType != tt.wantType || id.Mail != tt.wantDN) {
+				t.Errorf("Identity = %s (%s, mail %s), want %s (%s)", id.DN, id.UserType, id.Mail, tt.wantDN, tt.wantType)
+			}
+
+			var binds []string
+			for _, dn := range f.binds {
+				if dn != testBindDN {
+					binds = append(binds, dn)
+				}
+			}
+			if !reflect.DeepEqual(binds, tt.wantBinds) {
+				t.Errorf("user binds = %q, want %q", binds, tt.wantBinds)
+			}
+			if f.bound != testBindDN {
+				t.Errorf("connection left bound as %q, want the service account", f.bound)
+			}
+			if len(a.pool.idle) != 1 {
+				t.Errorf("%d idle connections after the login, want 1", len(a.pool.idle))
+			}
+		})
+	}
+}
+
+func TestLDAPAuthenticateEmptyPassword(t *testing.T) {
+	f := &fakeLDAP{entries: map[string][]string{testOUs[0].BaseDN: {"uid=alice,ou=staff,dc=example"}}}
+	a := newFakeAuthenticator(f)
+
+	if _, err := a.Authenticate(context.Background(), "alice", ""); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("Authenticate with no password error = %v, want ErrWrongPassword", err)
+	}
+	if len(f.filters) != 0 || len(f.binds) != 0 {
+		t.Errorf("searched %q and bound %q, want no request", f.filters, f.binds)
+	}
+}
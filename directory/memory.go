@@ -0,0 +1,105 @@
+// Package directory provides MemoryDirectory, an in-memory Authenticator
+// for tests and for running /HRldap without an LDAP server.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package directory
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MemoryEntry is a user of a MemoryDirectory.
+type MemoryEntry struct {
+	Identity
+	Password string
+}
+
+// MemoryDirectory is an Authenticator backed by a map of users.
+type MemoryDirectory struct {
+	mu      sync.RWMutex
+	entries map[string]MemoryEntry
+}
+
+// NewMemoryDirectory returns a directory holding the given entries.
+func NewMemoryDirectory(entries ...MemoryEntry) *MemoryDirectory {
+	d := &MemoryDirectory{entries: make(map[string]MemoryEntry)}
+	for _, e := range entries {
+		d.Add(e)
+	}
+	return d
+}
+
+// Add adds or replaces an entry, keyed by its Username.
+func (d *MemoryDirectory) Add(e MemoryEntry) {
+	if e.DN == "" {
+		e.DN = "uid=" + e.Username + ",ou=" + string(e.UserType)
+	}
+	d.mu.Lock()
+	d.entries[e.Username] = e
+	d.mu.Unlock()
+}
+
+// Remove deletes the entry of username.
+func (d *MemoryDirectory) Remove(username string) {
+	d.mu.Lock()
+	delete(d.entries, username)
+	d.mu.Unlock()
+}
+
+// Authenticate implements Authenticator.
+func (d *MemoryDirectory) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if !validUsername(username) {
+		return nil, ErrInvalidUsername
+	}
+
+	d.mu.RLock()
+	e, ok := d.entries[username]
+	d.mu.RUnlock()
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	if password == "" || subtle.ConstantTimeCompare([]byte(e.Password), []byte(password)) != 1 {
+		return nil, ErrWrongPassword
+	}
+
+	id := e.Identity
+	id.MemberOf = append([]string(nil), e.MemberOf...)
+	return &id, nil
+}
+
+// MemoryDirectoryFrom builds a MemoryDirectory from DIRECTORY_MEMORY_USERS,
+// a semicolon separated list of username:password:ou entries.
+func MemoryDirectoryFrom(users string) (*MemoryDirectory, error) {
+	d := NewMemoryDirectory()
+	for _, part := range strings.Split(users, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.SplitN(part, ":", 3)
+		if len(fields) != 3 || fields[0] == "" || fields[2] == "" {
+			return nil, fmt.Errorf("invalid DIRECTORY_MEMORY_USERS entry %q, want username:password:ou", part)
+		}
+		d.Add(MemoryEntry{
+			Identity: Identity{Username: fields[0], UserType: UserType(fields[2])},
+			Password: fields[1],
+		})
+	}
+	return d, nil
+}
@@ -0,0 +1,263 @@
+// Package directory provides the LDAP connection pool used by
+// LDAPAuthenticator.
+//
+// It ensures:
+//   - Connections use ldaps:// or StartTLS, verified against LDAP_CA_FILE
+//   - At most PoolSize connections are in use and PoolSize kept idle for reuse
+//   - Idle connections are health-checked before reuse
+//   - Servers are tried in order, skipping those whose circuit breaker is open
+//   - Dial, bind and search are bounded by timeouts
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package directory
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	ldap "github.com/go-ldap/ldap/v3"
+)
+
+// ErrUnavailable is returned when no LDAP server can be used.
+var ErrUnavailable = errors.New("no LDAP server available")
+
+// TLS modes of LDAPConfig.TLSMode.
+const (
+	TLSModeStartTLS = "starttls" // upgrade ldap:// connections with StartTLS
+	TLSModeLDAPS    = "ldaps"    // ldaps:// URLs, TLS from the first byte
+	TLSModeNone     = "none"     // plaintext; only for local test servers
+)
+
+// breaker is the circuit breaker of one server. After Threshold consecutive
+// failures it opens for Cooldown; then a single trial connection is let
+// through, which closes it again on success.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+}
+
+// allow reports whether a connection attempt may be made now.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	now := time.Now()
+	if now.Before(b.openUntil) {
+		return false
+	}
+	// Half-open: hold the others back while this caller tries
+	b.openUntil = now.Add(b.cooldown)
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	b.failures = 0
+	b.mu.Unlock()
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+	b.mu.Unlock()
+}
+
+// server is one entry of LDAPConfig.URLs.
+type server struct {
+	url     string
+	tls     *tls.Config // ServerName set to the URL host
+	breaker *breaker
+}
+
+// pooledConn is a connection bound as the service account. It holds an
+// ldap.Client rather than *ldap.Conn so tests can supply a fake server.
+type pooledConn struct {
+	ldap.Client
+	server   *server
+	lastUsed time.Time
+}
+
+// connPool is a bounded pool of service-bound connections.
+type connPool struct {
+	cfg     *LDAPConfig
+	servers []*server
+	slots   chan struct{} // one token per connection in use
+	mu      sync.Mutex
+	idle    []*pooledConn
+}
+
+// newConnPool prepares the servers of cfg; no connection is opened yet.
+func newConnPool(cfg *LDAPConfig) (*connPool, error) {
+	base := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", cfg.CAFile)
+		}
+		base.RootCAs = pool
+	}
+
+	p := &connPool{cfg: cfg, slots: make(chan struct{}, cfg.PoolSize)}
+	for _, raw := range cfg.URLs {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			return nil, fmt.Errorf("invalid LDAP URL %q", raw)
+		}
+		switch {
+		case u.Scheme == "ldaps" && cfg.TLSMode != TLSModeLDAPS,
+			u.Scheme == "ldap" && cfg.TLSMode == TLSModeLDAPS:
+			return nil, fmt.Errorf("LDAP URL %q does not match TLS mode %q", raw, cfg.TLSMode)
+		}
+		t := base.Clone()
+		t.ServerName = u.Hostname()
+		p.servers = append(p.servers, &server{
+			url:     raw,
+			tls:     t,
+			breaker: &breaker{threshold: cfg.BreakerThreshold, cooldown: cfg.BreakerCooldown},
+		})
+	}
+	return p, nil
+}
+
+// dial opens a connection to s, secures it and binds the service account.
+func (p *connPool) dial(s *server) (*pooledConn, error) {
+	dialer := &net.Dialer{Timeout: p.cfg.DialTimeout}
+	opts := []ldap.DialOpt{ldap.DialWithDialer(dialer)}
+	if p.cfg.TLSMode == TLSModeLDAPS {
+		opts = append(opts, ldap.DialWithTLSConfig(s.tls))
+	}
+
+	conn, err := ldap.DialURL(s.url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(p.cfg.RequestTimeout)
+
+	if p.cfg.TLSMode == TLSModeStartTLS {
+		if err := conn.StartTLS(s.tls); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("StartTLS: %v", err)
+		}
+	}
+
+	if err := conn.Bind(p.cfg.BindDN, p.cfg.BindPassword); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("service account bind failed: %v", err)
+	}
+	return &pooledConn{Client: conn, server: s, lastUsed: time.Now()}, nil
+}
+
+// healthy checks an idle connection with a base search of the root DSE.
+func (p *connPool) healthy(pc *pooledConn) bool {
+	if pc.IsClosing() {
+		return false
+	}
+	if time.Since(pc.lastUsed) < p.cfg.HealthCheckAfter {
+		return true
+	}
+	req := ldap.NewSearchRequest("", ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1,
+		int(p.cfg.RequestTimeout.Seconds()), false, "(objectClass=*)", []string{"1.1"}, nil)
+	_, err := pc.Search(req)
+	return err == nil
+}
+
+// get returns a service-bound connection, reusing an idle one when possible.
+// skip lists servers that already failed for this request.
+func (p *connPool) get(ctx context.Context, skip map[*server]bool) (*pooledConn, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	for {
+		p.mu.Lock()
+		n := len(p.idle)
+		if n == 0 {
+			p.mu.Unlock()
+			break
+		}
+		pc := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+
+		if !skip[pc.server] && p.healthy(pc) {
+			return pc, nil
+		}
+		pc.Close()
+	}
+
+	var lastErr error
+	for _, s := range p.servers {
+		if skip[s] || !s.breaker.allow() {
+			continue
+		}
+		pc, err := p.dial(s)
+		if err != nil {
+			log.Printf("LDAP server %s unavailable: %v", s.url, err)
+			s.breaker.failure()
+			lastErr = err
+			continue
+		}
+		s.breaker.success()
+		return pc, nil
+	}
+
+	<-p.slots
+	if lastErr != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, lastErr)
+	}
+	return nil, ErrUnavailable
+}
+
+// put returns pc to the pool, or closes it if it is no longer usable.
+func (p *connPool) put(pc *pooledConn, reusable bool) {
+	if reusable && !pc.IsClosing() {
+		pc.lastUsed = time.Now()
+		p.mu.Lock()
+		if len(p.idle) < p.cfg.PoolSize {
+			p.idle = append(p.idle, pc)
+			pc = nil
+		}
+		p.mu.Unlock()
+	}
+	if pc != nil {
+		pc.Close()
+	}
+	<-p.slots
+}
+
+// fail records a network failure of pc's server and closes pc.
+func (p *connPool) fail(pc *pooledConn) {
+	pc.server.breaker.failure()
+	p.put(pc, false)
+}
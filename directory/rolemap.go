@@ -0,0 +1,113 @@
+// Package directory provides the mapping from directory attributes to
+// application roles (ROLEMASTER.ROLENAME), applied at login.
+//
+// Rules come from ROLE_MAPPING, separated by ";", each written as
+//
+//	attribute:value=>Role A|Role B
+//
+// where attribute is memberOf, ou (the UserType) or employeeType, and value
+// is compared case-insensitively with the whole attribute value, e.g.
+//
+//	ou:faculty=>Faculty;memberOf:cn=hods,ou=groups,dc=ldap,dc=iitm,dc=ac,dc=in=>HOD
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package directory
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Attributes a RoleRule can match on.
+const (
+	RuleAttrMemberOf     = "memberof"
+	RuleAttrOU           = "ou"
+	RuleAttrEmployeeType = "employeetype"
+)
+
+// RoleRule grants Roles to identities whose Attribute equals Value.
+type RoleRule struct {
+	Attribute string // one of the RuleAttr constants
+	Value     string
+	Roles     []string
+}
+
+// ParseRoleRules parses the ROLE_MAPPING format described above.
+func ParseRoleRules(v string) ([]RoleRule, error) {
+	var rules []RoleRule
+	for _, part := range strings.Split(v, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		match, roles, ok := strings.Cut(part, "=>")
+		if !ok {
+			return nil, fmt.Errorf("rule %q: missing =>", part)
+		}
+		attr, value, ok := strings.Cut(match, ":")
+		if !ok || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("rule %q: want attribute:value", part)
+		}
+
+		rule := RoleRule{Attribute: strings.ToLower(strings.TrimSpace(attr)), Value: strings.TrimSpace(value)}
+		switch rule.Attribute {
+		case RuleAttrMemberOf, RuleAttrOU, RuleAttrEmployeeType:
+		default:
+			return nil, fmt.Errorf("rule %q: attribute must be memberOf, ou or employeeType", part)
+		}
+		for _, role := range strings.Split(roles, "|") {
+			if role = strings.TrimSpace(role); role != "" {
+				rule.Roles = append(rule.Roles, role)
+			}
+		}
+		if len(rule.Roles) == 0 {
+			return nil, fmt.Errorf("rule %q: no roles", part)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// matches reports whether the rule applies to id.
+func (r RoleRule) matches(id *Identity) bool {
+	switch r.Attribute {
+	case RuleAttrOU:
+		return strings.EqualFold(string(id.UserType), r.Value)
+	case RuleAttrEmployeeType:
+		return strings.EqualFold(id.EmployeeType, r.Value)
+	case RuleAttrMemberOf:
+		for _, group := range id.MemberOf {
+			if strings.EqualFold(group, r.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MapRoles returns the distinct role names the rules grant to id, in rule order.
+func MapRoles(id *Identity, rules []RoleRule) []string {
+	var roles []string
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if !rule.matches(id) {
+			continue
+		}
+		for _, role := range rule.Roles {
+			key := strings.ToLower(role)
+			if !seen[key] {
+				seen[key] = true
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
@@ -0,0 +1,87 @@
+package directory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRoleRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []RoleRule
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"ou rule", "ou:faculty=>Faculty", []RoleRule{{RuleAttrOU, "faculty", []string{"Faculty"}}}, false},
+		{
+			"group DN keeps its = and ,", "memberOf:cn=hods,ou=groups,dc=example=>HOD",
+			[]RoleRule{{RuleAttrMemberOf, "cn=hods,ou=groups,dc=example", []string{"HOD"}}}, false,
+		},
+		{
+			"several roles and rules", " employeeType : Regular => Employee | Approver ;; ou:staff=>Staff ",
+			[]RoleRule{
+				{RuleAttrEmployeeType, "Regular", []string{"Employee", "Approver"}},
+				{RuleAttrOU, "staff", []string{"Staff"}},
+			}, false,
+		},
+		{"empty roles skipped", "ou:staff=>Staff||", []RoleRule{{RuleAttrOU, "staff", []string{"Staff"}}}, false},
+		{"missing =>", "ou:staff=Staff", nil, true},
+		{"missing :", "staff=>Staff", nil, true},
+		{"empty value", "ou: =>Staff", nil, true},
+		{"unknown attribute", "mail:a@example.edu=>Staff", nil, true},
+		{"no roles", "ou:staff=> | ", nil, true},
+		{"one bad rule", "ou:staff=>Staff;ou:faculty", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRoleRules(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRoleRules(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRoleRules(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapRoles(t *testing.T) {
+	rules, err := ParseRoleRules(
+		"ou:faculty=>Faculty;" +
+			"memberOf:cn=hods,ou=groups,dc=example=>HOD|Approver;" +
+			"employeeType:Regular=>Employee;" +
+			"ou:staff=>Employee|Staff;" +
+			"memberOf:cn=approvers,ou=groups,dc=example=>approver")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		id   Identity
+		want []string
+	}{
+		{"no rule matches", Identity{UserType: "student", EmployeeType: "Temporary"}, nil},
+		{"ou", Identity{UserType: "faculty"}, []string{"Faculty"}},
+		{"ou ignores case", Identity{UserType: "FACULTY"}, []string{"Faculty"}},
+		{"group", Identity{UserType: "project", MemberOf: []string{"cn=staff,dc=example", "CN=HODs,OU=Groups,DC=example"}}, []string{"HOD", "Approver"}},
+		{"group must match the whole DN", Identity{MemberOf: []string{"cn=hods,ou=groups"}}, nil},
+		{"employee type", Identity{UserType: "project", EmployeeType: "regular"}, []string{"Employee"}},
+		{
+			"rule order and duplicates", Identity{UserType: "staff", EmployeeType: "Regular", MemberOf: []string{"cn=approvers,ou=groups,dc=example", "cn=hods,ou=groups,dc=example"}},
+			[]string{"HOD", "Approver", "Employee", "Staff"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MapRoles(&tt.id, rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MapRoles = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := MapRoles(&Identity{UserType: "faculty"}, nil); got != nil {
+		t.Errorf("MapRoles with no rules = %q, want none", got)
+	}
+}
@@ -2,15 +2,20 @@ module Hrmodule
 
 go 1.24
 
-require github.com/denisenkom/go-mssqldb v0.12.3
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/denisenkom/go-mssqldb v0.12.3
+	github.com/go-ldap/ldap/v3 v3.4.11
+	github.com/google/uuid v1.6.0
+	github.com/joho/godotenv v1.5.1
+	github.com/rs/cors v1.11.1
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358 // indirect
 	github.com/go-asn1-ber/asn1-ber v1.5.8-0.20250403174932-29230038a667 // indirect
-	github.com/go-ldap/ldap/v3 v3.4.11 // indirect
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
-	github.com/rs/cors v1.11.1 // indirect
 )
 
 require (
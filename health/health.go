@@ -0,0 +1,173 @@
+// Package health checks the dependencies the service needs to answer
+// requests: the database pools, the directory, the encryption key and the
+// TLS certificate.
+//
+// main registers one Check per dependency at startup. Readiness runs all of
+// them in parallel, each bounded by HEALTH_CHECK_TIMEOUT, and caches the
+// report for HEALTH_CACHE_TTL so that load balancer probes and monitoring
+// do not reach the databases more than once per period. Concurrent callers
+// during a run wait for it instead of starting their own.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package health
+
+import (
+	"Hrmodule/config"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status values of a check and of the report.
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+)
+
+// Check is one dependency. Run returns an optional detail shown in the
+// report, and an error when the dependency is not usable.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report is the outcome of all checks. The service is ready when every
+// check passed.
+type Report struct {
+	Status    string    `json:"status"`
+	CheckedAt time.Time `json:"checked_at"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Ready reports whether every check passed.
+func (r Report) Ready() bool {
+	return r.Status == StatusReady
+}
+
+var (
+	checks      []Check
+	cacheTTL    = 10 * time.Second
+	timeout     = 3 * time.Second
+	minValidity = 7 * 24 * time.Hour
+
+	mu     sync.Mutex
+	cached *Report
+)
+
+// Configure applies HEALTH_CACHE_TTL, HEALTH_CHECK_TIMEOUT and
+// HEALTH_CERT_MIN_VALIDITY.
+func Configure(cfg *config.Config) error {
+	cacheTTL = cfg.Health.CacheTTL
+	timeout = cfg.Health.CheckTimeout
+	minValidity = cfg.Health.CertMinValidity
+	return nil
+}
+
+// Register adds a check. It is called from main before the server starts.
+func Register(c Check) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks = append(checks, c)
+	cached = nil
+}
+
+// Readiness returns the cached report, running the checks when it is older
+// than HEALTH_CACHE_TTL.
+func Readiness(ctx context.Context) Report {
+	mu.Lock()
+	defer mu.Unlock()
+	if cached != nil && time.Since(cached.CheckedAt) < cacheTTL {
+		return *cached
+	}
+	// A probe that gives up must not cache its cancellation as a failure
+	report := run(context.WithoutCancel(ctx))
+	cached = &report
+	return report
+}
+
+// run runs every check in parallel.
+func run(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusReady,
+		CheckedAt: time.Now(),
+		Checks:    make([]Result, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			detail, err := c.Run(checkCtx)
+			result := Result{
+				Name:       c.Name,
+				Status:     StatusOK,
+				Detail:     detail,
+				DurationMs: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				result.Status = StatusFail
+				result.Error = err.Error()
+			}
+			report.Checks[i] = result
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusNotReady
+		}
+	}
+	return report
+}
+
+// Ping adapts a function such as (*sql.DB).PingContext to a Check.
+func Ping(ping func(ctx context.Context) error) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		return "", ping(ctx)
+	}
+}
+
+// Certificate returns a check that fails when the certificate returned by
+// leaf expires within HEALTH_CERT_MIN_VALIDITY.
+func Certificate(leaf func() *x509.Certificate) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		cert := leaf()
+		if cert == nil {
+			return "", errors.New("no certificate loaded")
+		}
+		remaining := time.Until(cert.NotAfter)
+		detail := fmt.Sprintf("expires %s", cert.NotAfter.UTC().Format(time.RFC3339))
+		if remaining < minValidity {
+			return detail, fmt.Errorf("certificate expires in %s, less than %s", remaining.Round(time.Minute), minValidity)
+		}
+		return detail, nil
+	}
+}
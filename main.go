@@ -6,11 +6,115 @@
 package main
 
 import (
-"Hrmodule/routes"
+	"Hrmodule/auth"
+	"Hrmodule/config"
+	controllerscommon "Hrmodule/controllers/common"
+	controllerslogin "Hrmodule/controllers/login"
+	databasecommon "Hrmodule/database/common"
+	databaselogin "Hrmodule/database/login"
+	credentials "Hrmodule/dbconfig"
+	"Hrmodule/health"
+	"Hrmodule/otp"
+	"Hrmodule/routes"
+	"Hrmodule/utils"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
 )
-//new
+
 // main is the entry point of the application.
-// It calls Registerroutes to bind API endpoints and start the server.
+// It loads the configuration (CONFIG_FILE names an optional YAML or TOML
+// file), applies it to every package and reports all problems at once,
+// opens the database pools and hands them to the packages using them,
+// starts the session activity flusher and the idle-session reaper, then
+// serves the routes from Registerroutes until SIGTERM or SIGINT, drains
+// in-flight requests and closes the pools.
 func main() {
-	routes.Registerroutes()
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, line := range cfg.Summary() {
+		log.Println("config:", line)
+	}
+
+	var errs config.Errors
+	for _, configure := range []func(*config.Config) error{
+		utils.Configure,
+		otp.Configure,
+		auth.Configure,
+		controllerslogin.Configure,
+		controllerscommon.Configure,
+		health.Configure,
+	} {
+		if err := configure(cfg); err != nil {
+			var list config.Errors
+			if errors.As(err, &list) {
+				errs = append(errs, list...)
+			} else {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		log.Fatal(errs)
+	}
+
+	pools, err := credentials.OpenPools(cfg)
+	if err != nil {
+		log.Fatalf("Failed to open database pools: %v", err)
+	}
+
+	databaselogin.UsePools(pools)
+	databasecommon.UsePools(pools)
+	controllerslogin.UsePools(pools)
+	controllerscommon.UsePools(pools)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	auth.StartActivityFlusher(ctx)
+	controllerslogin.StartSessionReaper(ctx)
+
+	// The certificate is needed by every listener that terminates TLS
+	var certs *routes.CertManager
+	if !cfg.Server.BehindProxy || cfg.Server.MTLSPort != "" {
+		if certs, err = routes.NewCertManager(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile); err != nil {
+			log.Fatal(err)
+		}
+		certs.Watch(ctx, cfg.Server.TLSReloadInterval)
+	}
+
+	// Dependencies checked by /readyz and /HealthReport
+	for _, name := range pools.Names() {
+		health.Register(health.Check{Name: "database:" + name, Run: health.Ping(pools.DB(name).PingContext)})
+	}
+	health.Register(health.Check{Name: "directory", Run: health.Ping(controllerslogin.PingDirectory)})
+	health.Register(health.Check{Name: "encryption-key", Run: health.Ping(func(context.Context) error {
+		return utils.SelfTest()
+	})})
+	if certs != nil {
+		health.Register(health.Check{Name: "tls-certificate", Run: health.Certificate(certs.Leaf)})
+	}
+
+	servers, err := routes.NewServers(cfg, routes.Registerroutes(cfg), certs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := routes.Serve(ctx, cfg, servers...); err != nil {
+		log.Printf("Server error: %v", err)
+	}
+
+	// The requests have drained; write the last session activity before
+	// the pools go away.
+	if err := auth.FlushSessionActivity(); err != nil {
+		log.Printf("Session activity flush failed: %v", err)
+	}
+	if err := pools.Close(); err != nil {
+		log.Printf("Closing database pools: %v", err)
+	}
+	log.Println("Server stopped")
 }
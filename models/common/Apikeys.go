@@ -0,0 +1,91 @@
+// Package modelscommon contains the queries of the API-key validation that
+// replaced the API_Validation_New procedure. They run on the API validation
+// (MySQL) database; dates are read as Unix seconds so that no parseTime
+// setting is needed on the connection.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package modelscommon
+
+// MyQueryAPIKeyByHash returns a key and its vendor by key hash
+const MyQueryAPIKeyByHash = `
+SELECT k.key_id, k.vendor_id, v.vendor_name, k.key_prefix,
+       k.is_active, UNIX_TIMESTAMP(k.expires_on),
+       v.is_active, UNIX_TIMESTAMP(v.active_from), UNIX_TIMESTAMP(v.active_until)
+FROM api_key k
+JOIN api_vendor v ON v.vendor_id = k.vendor_id
+WHERE k.key_hash = ?
+`
+
+// MyQueryVendorAPIs lists the APIs granted to a vendor with both activation windows
+const MyQueryVendorAPIs = `
+SELECT m.api_name,
+       m.is_active, UNIX_TIMESTAMP(m.active_from), UNIX_TIMESTAMP(m.active_until),
+       va.is_active, UNIX_TIMESTAMP(va.active_from), UNIX_TIMESTAMP(va.active_until)
+FROM api_vendor_api va
+JOIN api_master m ON m.api_name = va.api_name
+WHERE va.vendor_id = ?
+`
+
+// MyQueryVendorIPs lists the active allowlist entries of a vendor
+const MyQueryVendorIPs = `
+SELECT cidr
+FROM api_vendor_ip
+WHERE vendor_id = ? AND is_active = 1
+`
+
+// MyQueryInsertAPIKey stores a newly issued key; a lifetime of 0 seconds means no expiry
+const MyQueryInsertAPIKey = `
+INSERT INTO api_key (vendor_id, key_hash, key_prefix, created_by, created_on, expires_on)
+VALUES (?, ?, ?, ?, NOW(), IF(? > 0, NOW() + INTERVAL ? SECOND, NULL))
+`
+
+// MyQueryAPIKeyForUpdate locks an active key before it is rotated
+const MyQueryAPIKeyForUpdate = `
+SELECT vendor_id
+FROM api_key
+WHERE key_id = ? AND is_active = 1
+FOR UPDATE
+`
+
+// MyQueryRetireAPIKey lets a rotated key run until the grace period ends
+const MyQueryRetireAPIKey = `
+UPDATE api_key
+SET replaced_by = ?,
+    expires_on = CASE
+        WHEN expires_on IS NULL OR expires_on > NOW() + INTERVAL ? SECOND
+        THEN NOW() + INTERVAL ? SECOND
+        ELSE expires_on
+    END
+WHERE key_id = ?
+`
+
+// MyQueryRevokeAPIKey deactivates a key immediately
+const MyQueryRevokeAPIKey = `
+UPDATE api_key
+SET is_active = 0, revoked_by = ?, revoked_on = NOW()
+WHERE key_id = ? AND is_active = 1
+`
+
+// MyQueryVendorIsActive checks that a vendor exists and is active
+const MyQueryVendorIsActive = `
+SELECT is_active
+FROM api_vendor
+WHERE vendor_id = ?
+`
+
+// MyQueryInsertClientRequest logs a validated request and its outcome
+const MyQueryInsertClientRequest = `
+INSERT INTO Client_Request (
+    Ip_Address, Request_Data, Response_Data,
+    Status, Error, Request_On, Response_On, Updated_On
+)
+VALUES (?, ?, '', ?, ?, NOW(), NOW(), NOW())
+`
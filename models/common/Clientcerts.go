@@ -0,0 +1,25 @@
+// Package modelscommon contains the query mapping client certificates of
+// the mutual-TLS listener to vendors. It runs on the API validation (MySQL)
+// database.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package modelscommon
+
+// MyQueryClientCertByFingerprint returns a registered client certificate and
+// its vendor by certificate fingerprint, in the columns of MyQueryAPIKeyByHash
+const MyQueryClientCertByFingerprint = `
+SELECT c.cert_id, c.vendor_id, v.vendor_name, LEFT(c.fingerprint, 8),
+       c.is_active, UNIX_TIMESTAMP(c.expires_on),
+       v.is_active, UNIX_TIMESTAMP(v.active_from), UNIX_TIMESTAMP(v.active_until)
+FROM api_vendor_cert c
+JOIN api_vendor v ON v.vendor_id = c.vendor_id
+WHERE c.fingerprint = ?
+`
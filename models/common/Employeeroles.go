@@ -6,9 +6,9 @@
 //
 // Created On:30-07-2025
 //
-// Last Modified By: Sivabala
+// Last Modified By: Sridharan
 //
-// Last Modified Date: 30-07-2025
+// Last Modified Date: 17-10-2026
 //
 // Path:Login Page
 package modelscommon
@@ -31,6 +31,17 @@ AND B.IsActive IN ('1','0')
 ORDER BY B.UPDATEDON ASC
 `
 
+// MyQueryActiveRoleNames returns the names of the roles a user currently holds
+const MyQueryActiveRoleNames = `
+SELECT DISTINCT D.ROLENAME
+FROM USERMASTER A
+JOIN ORGUNITUSERMAPPING B ON A.USERID = B.USERID
+JOIN ORGUNITROLEMAPPING C ON B.RoleMapId = C.ROLEMAPID
+JOIN ROLEMASTER D ON C.ROLEID = D.ROLEID
+WHERE A.UserName = $1
+AND B.IsActive = '1'
+`
+
 // DefaultRoleNamestructure defines the structure of DefaultRoleName
 type DefaultRoleNamestructure struct {
 	USERID   *string `json:"UserID"`
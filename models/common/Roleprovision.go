@@ -0,0 +1,60 @@
+// Package modelscommon contains the queries used to provision directory
+// mapped roles into ORGUNITUSERMAPPING at login.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+//
+// Path:Login Page
+package modelscommon
+
+// MyQueryUserIdByName returns the USERMASTER id of a username
+const MyQueryUserIdByName = `
+SELECT USERID
+FROM USERMASTER
+WHERE UserName = $1
+`
+
+// MyQueryRoleMapIdByRoleName returns the ORGUNITROLEMAPPING id used for a role name.
+// When a role is mapped to several org units the lowest ROLEMAPID is used.
+const MyQueryRoleMapIdByRoleName = `
+SELECT MIN(C.ROLEMAPID)
+FROM ORGUNITROLEMAPPING C
+JOIN ROLEMASTER D ON C.ROLEID = D.ROLEID
+WHERE LOWER(D.ROLENAME) = LOWER($1)
+`
+
+// MyQueryUserRoleMappings lists a user's mappings with their role name and source, locked for update
+const MyQueryUserRoleMappings = `
+SELECT B.RoleMapId, D.ROLENAME, COALESCE(B.IsActive, '0'), COALESCE(B.ProvisionSource, '')
+FROM ORGUNITUSERMAPPING B
+JOIN ORGUNITROLEMAPPING C ON B.RoleMapId = C.ROLEMAPID
+JOIN ROLEMASTER D ON C.ROLEID = D.ROLEID
+WHERE B.USERID = $1
+FOR UPDATE OF B
+`
+
+// MyQueryInsertProvisionedRole grants a role mapping provisioned from the directory
+const MyQueryInsertProvisionedRole = `
+INSERT INTO ORGUNITUSERMAPPING (USERID, RoleMapId, IsActive, UPDATEDON, ProvisionSource)
+VALUES ($1, $2, '1', NOW(), $3)
+`
+
+// MyQuerySetProvisionedRoleActive activates or deactivates a directory provisioned mapping
+const MyQuerySetProvisionedRoleActive = `
+UPDATE ORGUNITUSERMAPPING
+SET IsActive = $3, UPDATEDON = NOW()
+WHERE USERID = $1 AND RoleMapId = $2 AND ProvisionSource = $4
+`
+
+// MyQueryInsertRoleProvisionAudit records one provisioning change
+const MyQueryInsertRoleProvisionAudit = `
+INSERT INTO role_provision_audit (username, role_name, action, source, detail, created_on)
+VALUES ($1, $2, $3, $4, $5, NOW())
+`
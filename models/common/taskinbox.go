@@ -8,7 +8,7 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 26-08-2025
+// Last Modified Date: 17-10-2026
 //
 // Path:Task Inbox  Page
 package modelscommon
@@ -23,6 +23,14 @@ SELECT *
 FROM public.getinboxtasks_role($1, $2)
 `
 
+// MyQueryInboxCoverPage checks whether a cover page is in an employee's inbox for a role
+const MyQueryInboxCoverPage = `
+SELECT 1
+FROM public.getinboxtasks_role($1, $2)
+WHERE coverpageno = $3
+LIMIT 1
+`
+
 // InboxTasksRole defines the structure for getinboxtasks_role output
 type InboxTasksRole struct {
 	TaskID        *string `json:"taskid"`
@@ -0,0 +1,28 @@
+// Package modelslogin contains the queries of the impersonation audit trail.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package modelslogin
+
+// MyQueryInsertImpersonationAudit records a request made with an impersonation token
+const MyQueryInsertImpersonationAudit = `
+INSERT INTO impersonation_audit
+	(actor_username, actor_employee_id, session_id, target_username, target_employee_id,
+	 method, route, allowed, client_ip, created_on)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+RETURNING id
+`
+
+// MyQueryCompleteImpersonationAudit stores the status an audited request ended with
+const MyQueryCompleteImpersonationAudit = `
+UPDATE impersonation_audit
+SET status = $2, completed_on = NOW()
+WHERE id = $1
+`
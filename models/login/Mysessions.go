@@ -0,0 +1,77 @@
+// Package modelslogin contains the queries and structures of the My Sessions page.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+//
+// Path: My Sessions
+package modelslogin
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MyQueryEmployeeSessions lists an employee's active sessions followed by the
+// most recent closed ones, newest first
+const MyQueryEmployeeSessions = `
+SELECT session_id, device_label, user_agent, client_ip, is_active, login_date, logout_date, logout_reason
+FROM session_data
+WHERE employee_id = $1
+ORDER BY (is_active = 1) DESC, login_date DESC
+LIMIT $2
+`
+
+// MyQuerySessionOwner returns the employee and the Is_Active flag of a session
+const MyQuerySessionOwner = `
+SELECT employee_id, is_active
+FROM session_data
+WHERE session_id = $1
+`
+
+// MySessionStructure is one row of the My Sessions list
+type MySessionStructure struct {
+	SessionID    *string `json:"session_id"`
+	DeviceLabel  *string `json:"device_label"`
+	UserAgent    *string `json:"user_agent"`
+	ClientIP     *string `json:"client_ip"`
+	IsActive     *int    `json:"is_active"`
+	LoginDate    *string `json:"login_date"`
+	LogoutDate   *string `json:"logout_date"`
+	LogoutReason *string `json:"logout_reason"`
+	Current      bool    `json:"current"` // session of the calling JWT
+}
+
+// RetrieveMySessions scans rows into MySessionStructure slice
+func RetrieveMySessions(rows *sql.Rows) ([]MySessionStructure, error) {
+	var sessions []MySessionStructure
+
+	for rows.Next() {
+		var s MySessionStructure
+		err := rows.Scan(
+			&s.SessionID,
+			&s.DeviceLabel,
+			&s.UserAgent,
+			&s.ClientIP,
+			&s.IsActive,
+			&s.LoginDate,
+			&s.LogoutDate,
+			&s.LogoutReason,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+
+	return sessions, nil
+}
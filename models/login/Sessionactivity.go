@@ -0,0 +1,33 @@
+// Package modelslogin contains the queries used for server-side idle timeout.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package modelslogin
+
+// MyQueryTouchSessions stores the last activity of a batch of active sessions.
+// $1 holds session ids and $2 the matching unix times; older values never
+// overwrite newer ones written by another instance.
+const MyQueryTouchSessions = `
+UPDATE session_data s
+SET last_activity = to_timestamp(v.seen)::timestamp
+FROM unnest($1::text[], $2::float8[]) AS v(session_id, seen)
+WHERE s.session_id = v.session_id
+  AND s.is_active = 1
+  AND (s.last_activity IS NULL OR s.last_activity < to_timestamp(v.seen)::timestamp)
+`
+
+// MyQueryIdleSessions lists active sessions idle for at least $1 seconds
+// with their idle time in seconds. Sessions never touched count from login.
+const MyQueryIdleSessions = `
+SELECT session_id, username, EXTRACT(EPOCH FROM NOW() - COALESCE(last_activity, login_date))
+FROM session_data
+WHERE is_active = 1
+  AND COALESCE(last_activity, login_date) < NOW() - $1 * interval '1 second'
+`
@@ -8,7 +8,7 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 25-08-2025
+// Last Modified Date: 17-10-2026
 package modelslogin
 
 import (
@@ -19,23 +19,31 @@ import (
 )
 
 const MyQuerySessionData = `
-SELECT id, session_id, department, username, user_id, employee_id, is_active, idletimeout, login_date, logout_date
+SELECT id, session_id, department, username, user_id, employee_id, is_active, idletimeout, login_date, logout_date, logout_reason
+FROM session_data
+WHERE session_id = $1
+`
+
+// MyQuerySessionActive returns the Is_Active flag of a session
+const MyQuerySessionActive = `
+SELECT is_active
 FROM session_data
 WHERE session_id = $1
 `
 
 // SessionDataStructure defines the structure of session_data
 type SessionDataStructure struct {
-	ID         *int64  `json:"id"`
-	SessionID  *string `json:"session_id"`
-	Department *string `json:"department"`
-	Username   *string `json:"username"`
-	UserID     *string `json:"user_id"`
-	EmployeeID *string `json:"employee_id"`
-	IsActive   *int    `json:"is_active"`
-	IdleTime   *int64  `json:"idletimeout"`
-	LoginDate  *string `json:"login_date"`
-	LogoutDate *string `json:"logout_date"`
+	ID           *int64  `json:"id"`
+	SessionID    *string `json:"session_id"`
+	Department   *string `json:"department"`
+	Username     *string `json:"username"`
+	UserID       *string `json:"user_id"`
+	EmployeeID   *string `json:"employee_id"`
+	IsActive     *int    `json:"is_active"`
+	IdleTime     *int64  `json:"idletimeout"`
+	LoginDate    *string `json:"login_date"`
+	LogoutDate   *string `json:"logout_date"`
+	LogoutReason *string `json:"logout_reason"` // logout, idle_timeout, superseded, ...
 }
 
 // RetrieveSessionData scans rows into SessionDataStructure slice
@@ -55,6 +63,7 @@ func RetrieveSessionData(rows *sql.Rows) ([]SessionDataStructure, error) {
 			&s.IdleTime,
 			&s.LoginDate,
 			&s.LogoutDate,
+			&s.LogoutReason,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning row: %v", err)
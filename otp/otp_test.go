@@ -0,0 +1,121 @@
+package otp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, length := range []int{4, 6, 8} {
+		code, err := Generate(length)
+		if err != nil {
+			t.Fatalf("Generate(%d): %v", length, err)
+		}
+		if len(code) != length || strings.Trim(code, "0123456789") != "" {
+			t.Errorf("Generate(%d) = %q, want %d digits", length, code, length)
+		}
+	}
+	if _, err := Generate(0); err == nil {
+		t.Error("Generate(0) succeeded, want an error")
+	}
+}
+
+func TestHashIsSalted(t *testing.T) {
+	a, err := Hash("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Hash("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Fatalf("two hashes of the same code are equal: %q", a)
+	}
+
+	salt, sum, ok := strings.Cut(a, ":")
+	if !ok {
+		t.Fatalf("Hash = %q, want salt:hash", a)
+	}
+	if raw, err := hex.DecodeString(salt); err != nil || len(raw) != saltSize {
+		t.Errorf("salt = %q, want %d hex-encoded bytes", salt, saltSize)
+	}
+	if raw, err := hex.DecodeString(sum); err != nil || len(raw) != 32 {
+		t.Errorf("hash = %q, want a hex-encoded SHA-256", sum)
+	}
+	if strings.Contains(a, "123456") {
+		t.Errorf("Hash = %q contains the code", a)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	stored, err := Hash("042917")
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt, sum, _ := strings.Cut(stored, ":")
+
+	// Flip the last hash byte, so only a full comparison can tell them apart
+	last, _ := hex.DecodeString(sum[len(sum)-2:])
+	flipped := salt + ":" + sum[:len(sum)-2] + hex.EncodeToString([]byte{last[0] ^ 1})
+
+	other, err := Hash("042917")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherSum, _ := strings.Cut(other, ":")
+
+	tests := []struct {
+		name   string
+		code   string
+		stored string
+		want   bool
+	}{
+		{"matching code", "042917", stored, true},
+		{"wrong code", "042918", stored, false},
+		{"code without leading zero", "42917", stored, false},
+		{"empty code", "", stored, false},
+		{"last hash byte differs", "042917", flipped, false},
+		{"truncated hash", "042917", salt + ":" + sum[:len(sum)-2], false},
+		{"hash under another salt", "042917", salt + ":" + otherSum, false},
+		{"no separator", "042917", salt + sum, false},
+		{"salt not hex", "042917", "zz:" + sum, false},
+		{"hash not hex", "042917", salt + ":zz", false},
+		{"empty stored value", "042917", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.code, tt.stored); got != tt.want {
+				t.Errorf("Verify(%q, %q) = %v, want %v", tt.code, tt.stored, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    string
+		wantErr bool
+	}{
+		{`"042917"`, "042917", false},
+		{`" 042917 "`, "042917", false},
+		{`42917`, "042917", false},
+		{`123456`, "123456", false},
+		{`true`, "", true},
+		{`{}`, "", true},
+	}
+	for _, tt := range tests {
+		var c Code
+		err := json.Unmarshal([]byte(tt.json), &c)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+			continue
+		}
+		if got := c.Normalize(6); !tt.wantErr && got != tt.want {
+			t.Errorf("Unmarshal(%s).Normalize(6) = %q, want %q", tt.json, got, tt.want)
+		}
+	}
+}
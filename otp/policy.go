@@ -0,0 +1,117 @@
+// Package otp provides the configurable OTP policy: code length, validity
+// window, verification attempts, resend limits and lockout, and the
+// rejection reasons returned to the client when the policy is violated.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package otp
+
+import (
+	"Hrmodule/config"
+	"math"
+	"net/http"
+	"time"
+)
+
+// Policy holds the limits applied to OTP issue and verification.
+type Policy struct {
+	Length            int           // digits in a generated code
+	Validity          time.Duration // how long a code is accepted
+	MaxVerifyAttempts int           // wrong guesses allowed per code
+	ResendMinGap      time.Duration // minimum time between two codes for a session
+	MaxResends        int           // resends allowed per session after the first code
+	LockoutThreshold  int           // failed verifications per username or mobile before lockout
+	LockoutDuration   time.Duration // how long a lockout lasts; also the failure counting window
+}
+
+// CurrentPolicy is the policy in force, set from the OTP_* settings by Configure.
+var CurrentPolicy Policy
+
+// configurePolicy sets CurrentPolicy; config.Load has checked the limits.
+func configurePolicy(cfg *config.Config) {
+	CurrentPolicy = Policy{
+		Length:            cfg.OTP.Length,
+		Validity:          cfg.OTP.Validity,
+		MaxVerifyAttempts: cfg.OTP.MaxVerifyAttempts,
+		ResendMinGap:      cfg.OTP.ResendMinGap,
+		MaxResends:        cfg.OTP.MaxResends,
+		LockoutThreshold:  cfg.OTP.LockoutThreshold,
+		LockoutDuration:   cfg.OTP.LockoutDuration,
+	}
+}
+
+// CheckResend returns a rejection if a session that has already been sent
+// sent codes, the last one sinceLast ago, may not get another one yet.
+func (p Policy) CheckResend(sent int, sinceLast time.Duration) *Rejection {
+	if sent > p.MaxResends {
+		return Reject(ReasonResendLimit, "Maximum OTP resends reached, please log in again", 0)
+	}
+	if sent > 0 && sinceLast < p.ResendMinGap {
+		return Reject(ReasonResendTooSoon, "Please wait before requesting another OTP", p.ResendMinGap-sinceLast)
+	}
+	return nil
+}
+
+// CheckVerify returns a rejection if a code that has had attempts wrong
+// guesses and is valid for remaining more may no longer be verified.
+func (p Policy) CheckVerify(attempts int, remaining time.Duration) *Rejection {
+	if attempts >= p.MaxVerifyAttempts {
+		return Reject(ReasonAttemptsExceeded, "Too many wrong attempts, request a new OTP", 0)
+	}
+	if remaining < 0 {
+		return Reject(ReasonExpired, "OTP expired, request a new OTP", 0)
+	}
+	return nil
+}
+
+// Reason codes returned to the client when an OTP request is rejected.
+const (
+	ReasonInvalid          = "OTP_INVALID"           // code does not match
+	ReasonExpired          = "OTP_EXPIRED"           // validity window has passed
+	ReasonNotFound         = "OTP_NOT_FOUND"         // no pending code for the session
+	ReasonAttemptsExceeded = "OTP_ATTEMPTS_EXCEEDED" // too many wrong guesses for this code
+	ReasonResendTooSoon    = "OTP_RESEND_TOO_SOON"   // ResendMinGap not yet elapsed
+	ReasonResendLimit      = "OTP_RESEND_LIMIT"      // MaxResends reached for the session
+	ReasonLocked           = "OTP_LOCKED"            // username or mobile temporarily locked
+)
+
+// Rejection is returned when the policy refuses an OTP request.
+type Rejection struct {
+	Reason     string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (r *Rejection) Error() string {
+	return r.Reason + ": " + r.Message
+}
+
+// RetryAfterSeconds returns RetryAfter rounded up to whole seconds.
+func (r *Rejection) RetryAfterSeconds() int {
+	return int(math.Ceil(r.RetryAfter.Seconds()))
+}
+
+// StatusCode is 429 for throttling reasons and 200 for verification
+// failures, which the client already handles as "success": false.
+func (r *Rejection) StatusCode() int {
+	switch r.Reason {
+	case ReasonResendTooSoon, ReasonResendLimit, ReasonLocked:
+		return http.StatusTooManyRequests
+	}
+	return http.StatusOK
+}
+
+// Reject builds a Rejection.
+func Reject(reason, message string, retryAfter time.Duration) *Rejection {
+	if retryAfter < 0 {
+		retryAfter = 0
+	}
+	return &Rejection{Reason: reason, Message: message, RetryAfter: retryAfter}
+}
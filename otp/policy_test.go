@@ -0,0 +1,105 @@
+package otp
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+var testPolicy = Policy{
+	Length:            6,
+	Validity:          5 * time.Minute,
+	MaxVerifyAttempts: 3,
+	ResendMinGap:      30 * time.Second,
+	MaxResends:        2,
+	LockoutThreshold:  5,
+	LockoutDuration:   15 * time.Minute,
+}
+
+func TestPolicyCheckResend(t *testing.T) {
+	tests := []struct {
+		name       string
+		sent       int
+		sinceLast  time.Duration
+		wantReason string
+		wantRetry  time.Duration
+	}{
+		{"first code", 0, 0, "", 0},
+		{"resend after the gap", 1, 30 * time.Second, "", 0},
+		{"resend within the gap", 1, 10 * time.Second, ReasonResendTooSoon, 20 * time.Second},
+		{"last resend allowed", 2, time.Minute, "", 0},
+		{"resends used up", 3, time.Minute, ReasonResendLimit, 0},
+		{"limit wins over the gap", 3, time.Second, ReasonResendLimit, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rej := testPolicy.CheckResend(tt.sent, tt.sinceLast)
+			checkRejection(t, rej, tt.wantReason, tt.wantRetry)
+		})
+	}
+}
+
+func TestPolicyCheckVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempts   int
+		remaining  time.Duration
+		wantReason string
+	}{
+		{"fresh code", 0, testPolicy.Validity, ""},
+		{"last attempt", 2, time.Second, ""},
+		{"attempts used up", 3, time.Minute, ReasonAttemptsExceeded},
+		{"expired", 0, -time.Second, ReasonExpired},
+		{"attempts win over expiry", 3, -time.Second, ReasonAttemptsExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rej := testPolicy.CheckVerify(tt.attempts, tt.remaining)
+			checkRejection(t, rej, tt.wantReason, 0)
+		})
+	}
+}
+
+func TestRejection(t *testing.T) {
+	tests := []struct {
+		reason     string
+		retryAfter time.Duration
+		wantStatus int
+		wantRetry  int
+	}{
+		{ReasonInvalid, 0, http.StatusOK, 0},
+		{ReasonExpired, 0, http.StatusOK, 0},
+		{ReasonNotFound, 0, http.StatusOK, 0},
+		{ReasonAttemptsExceeded, 0, http.StatusOK, 0},
+		{ReasonResendTooSoon, 1500 * time.Millisecond, http.StatusTooManyRequests, 2},
+		{ReasonResendLimit, 0, http.StatusTooManyRequests, 0},
+		{ReasonLocked, -time.Second, http.StatusTooManyRequests, 0},
+	}
+	for _, tt := range tests {
+		rej := Reject(tt.reason, "message", tt.retryAfter)
+		if got := rej.StatusCode(); got != tt.wantStatus {
+			t.Errorf("%s: StatusCode() = %d, want %d", tt.reason, got, tt.wantStatus)
+		}
+		if got := rej.RetryAfterSeconds(); got != tt.wantRetry {
+			t.Errorf("%s: RetryAfterSeconds() = %d, want %d", tt.reason, got, tt.wantRetry)
+		}
+	}
+}
+
+// checkRejection fails t unless rej has the wanted reason and retry delay;
+// an empty wantReason means no rejection.
+func checkRejection(t *testing.T, rej *Rejection, wantReason string, wantRetry time.Duration) {
+	t.Helper()
+	if wantReason == "" {
+		if rej != nil {
+			t.Fatalf("rejected with %v, want accepted", rej)
+		}
+		return
+	}
+	if rej == nil {
+		t.Fatalf("accepted, want %s", wantReason)
+	}
+	if rej.Reason != wantReason || rej.RetryAfter != wantRetry {
+		t.Errorf("rejection = %s after %v, want %s after %v", rej.Reason, rej.RetryAfter, wantReason, wantRetry)
+	}
+}
@@ -0,0 +1,125 @@
+// Package otp provides the OTPSender interface through which generated
+// codes are handed to a delivery channel, and selection of the channel
+// from the OTP_SENDER setting:
+//
+//   - http:   the institute SMS gateway (see HTTPSender)
+//   - spool:  one JSON file per message in OTP_SPOOL_DIR (see SpoolSender)
+//   - memory: an in-process outbox (see MemorySender)
+//   - log:    the application log
+//
+// There is no default: OTP_SENDER must be set. log, spool and memory keep
+// the codes in plain text and are refused unless OTP_ALLOW_DEV_SENDERS is
+// set, so a missing setting can never leak codes into logs or files.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package otp
+
+import (
+	"Hrmodule/config"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is a single OTP to be delivered to a user.
+type Message struct {
+	OTPID    int           // id of the otp_details row
+	Username string        // login name the OTP was issued for
+	MobileNo string        // destination number from employeebasicinfo
+	Code     string        // the plain code; never stored or returned to the client
+	ValidFor time.Duration // how long the code is accepted
+}
+
+// Receipt describes the outcome of a delivery.
+type Receipt struct {
+	Attempts    int    // number of tries made, including the successful one
+	ProviderRef string // message id or response returned by the provider
+}
+
+// OTPSender delivers an OTP to the user.
+type OTPSender interface {
+	Send(ctx context.Context, msg Message) (Receipt, error)
+}
+
+// Sender is the channel used by the login handlers, chosen by OTP_SENDER.
+// It is nil until Configure succeeds.
+var Sender OTPSender
+
+// DefaultMessageTemplate is the SMS text used when OTP_SMS_MESSAGE is not set.
+// With a DLT-registered template the text must match the registration exactly.
+const DefaultMessageTemplate = "{otp} is your OTP for HR login. It is valid for {minutes} minute(s). Do not share it with anyone."
+
+// Configure applies the OTP policy and selects Sender from OTP_SENDER.
+func Configure(cfg *config.Config) error {
+	configurePolicy(cfg)
+
+	switch kind := cfg.OTP.Sender; kind {
+	case "":
+		return fmt.Errorf("OTP_SENDER: must be set")
+	case "log", "memory", "spool":
+		if !cfg.OTP.AllowDevSenders {
+			return fmt.Errorf("OTP_SENDER: %s is refused unless OTP_ALLOW_DEV_SENDERS is true", kind)
+		}
+	}
+
+	switch kind := cfg.OTP.Sender; kind {
+	case "log":
+		Sender = LogSender{}
+	case "memory":
+		Sender = &MemorySender{}
+	case "spool":
+		Sender = &SpoolSender{Dir: cfg.OTP.SpoolDir}
+	case "http":
+		Sender = httpSenderFromConfig(cfg.OTP.SMS)
+	default:
+		return fmt.Errorf("unsupported OTP_SENDER %q", kind)
+	}
+	return nil
+}
+
+// Render fills the placeholders {otp}, {minutes}, {seconds} and {username} of tmpl.
+func Render(tmpl string, msg Message) string {
+	minutes := int(msg.ValidFor.Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	return strings.NewReplacer(
+		"{otp}", msg.Code,
+		"{minutes}", strconv.Itoa(minutes),
+		"{seconds}", strconv.Itoa(int(msg.ValidFor.Seconds())),
+		"{username}", msg.Username,
+	).Replace(tmpl)
+}
+
+// LogSender writes OTPs to the application log. It is meant for local
+// development only and needs OTP_ALLOW_DEV_SENDERS.
+type LogSender struct{}
+
+// Send implements OTPSender.
+func (LogSender) Send(ctx context.Context, msg Message) (Receipt, error) {
+	log.Printf("OTP for %s to %s: %s (valid %s)", msg.Username, MaskMobile(msg.MobileNo), msg.Code, msg.ValidFor)
+	return Receipt{Attempts: 1, ProviderRef: "log"}, nil
+}
+
+// MaskMobile hides all but the last four digits of a mobile number.
+func MaskMobile(mobile string) string {
+	if len(mobile) <= 4 {
+		return mobile
+	}
+	masked := make([]byte, len(mobile)-4)
+	for i := range masked {
+		masked[i] = '*'
+	}
+	return string(masked) + mobile[len(mobile)-4:]
+}
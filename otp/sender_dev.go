@@ -0,0 +1,97 @@
+// Package otp provides the offline OTP senders used in development and
+// tests, so the whole login flow can run without the SMS gateway.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package otp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SpoolSender writes each message as a JSON file into Dir.
+type SpoolSender struct {
+	Dir string
+}
+
+// spoolFile is the content of one spooled message.
+type spoolFile struct {
+	OTPID    int       `json:"otp_id"`
+	Username string    `json:"username"`
+	MobileNo string    `json:"mobileno"`
+	Text     string    `json:"text"`
+	Code     string    `json:"otp"`
+	SentOn   time.Time `json:"sent_on"`
+}
+
+// Send implements OTPSender.
+func (s *SpoolSender) Send(ctx context.Context, msg Message) (Receipt, error) {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return Receipt{Attempts: 1}, err
+	}
+
+	data, err := json.MarshalIndent(spoolFile{
+		OTPID:    msg.OTPID,
+		Username: msg.Username,
+		MobileNo: msg.MobileNo,
+		Text:     Render(DefaultMessageTemplate, msg),
+		Code:     msg.Code,
+		SentOn:   time.Now(),
+	}, "", "    ")
+	if err != nil {
+		return Receipt{Attempts: 1}, err
+	}
+
+	name := fmt.Sprintf("%s-%d-%s.json", time.Now().Format("20060102T150405.000"), msg.OTPID, msg.Username)
+	path := filepath.Join(s.Dir, filepath.Base(name))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return Receipt{Attempts: 1}, err
+	}
+	return Receipt{Attempts: 1, ProviderRef: path}, nil
+}
+
+// MemorySender keeps messages in an in-memory outbox.
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Send implements OTPSender.
+func (s *MemorySender) Send(ctx context.Context, msg Message) (Receipt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+	return Receipt{Attempts: 1, ProviderRef: fmt.Sprintf("memory-%d", len(s.messages))}, nil
+}
+
+// Messages returns a copy of the outbox.
+func (s *MemorySender) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Last returns the most recent message sent to username.
+func (s *MemorySender) Last(username string) (Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].Username == username {
+			return s.messages[i], true
+		}
+	}
+	return Message{}, false
+}
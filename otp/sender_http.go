@@ -0,0 +1,176 @@
+// Package otp provides HTTPSender, the OTPSender for the institute SMS
+// gateway.
+//
+// The gateway request is described by templates so a provider change does
+// not need a code change. URL and body templates may use the placeholders
+// {mobile}, {message}, {template_id} and {sender_id}; values are URL
+// encoded in the URL and, for form and JSON bodies, escaped for the body.
+// Templates for JSON bodies put the placeholders inside string literals.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package otp
+
+import (
+	"Hrmodule/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPSender sends OTPs through an HTTP SMS gateway.
+type HTTPSender struct {
+	Method          string        // GET or POST
+	URLTemplate     string        // gateway URL with placeholders
+	BodyTemplate    string        // request body for POST, with placeholders
+	ContentType     string        // content type of the body
+	MessageTemplate string        // SMS text; see Render
+	TemplateID      string        // DLT template id
+	SenderID        string        // DLT sender id (header)
+	SuccessMatch    string        // optional text that must appear in a successful response
+	MaxAttempts     int           // tries before giving up
+	Backoff         time.Duration // wait before the second try, doubled after each failure
+	Client          *http.Client
+}
+
+// httpSenderFromConfig builds an HTTPSender from the OTP_SMS_* settings,
+// which config.Load has validated.
+func httpSenderFromConfig(sms config.SMS) *HTTPSender {
+	message := sms.Message
+	if message == "" {
+		message = DefaultMessageTemplate
+	}
+	return &HTTPSender{
+		Method:          strings.ToUpper(sms.Method),
+		URLTemplate:     sms.URL,
+		BodyTemplate:    sms.Body,
+		ContentType:     sms.ContentType,
+		MessageTemplate: message,
+		TemplateID:      sms.TemplateID,
+		SenderID:        sms.SenderID,
+		SuccessMatch:    sms.SuccessMatch,
+		MaxAttempts:     sms.MaxAttempts,
+		Backoff:         500 * time.Millisecond,
+		Client:          &http.Client{Timeout: sms.Timeout},
+	}
+}
+
+// errPermanent marks gateway responses that must not be retried.
+var errPermanent = errors.New("gateway rejected the message")
+
+// Send implements OTPSender. Network errors and 5xx responses are retried
+// with exponential backoff; 4xx responses are not.
+func (s *HTTPSender) Send(ctx context.Context, msg Message) (Receipt, error) {
+	text := Render(s.MessageTemplate, msg)
+
+	var receipt Receipt
+	var lastErr error
+	wait := s.Backoff
+	for attempt := 1; attempt <= s.MaxAttempts; attempt++ {
+		receipt.Attempts = attempt
+
+		ref, err := s.post(ctx, msg.MobileNo, text)
+		if err == nil {
+			receipt.ProviderRef = ref
+			return receipt, nil
+		}
+		lastErr = err
+		if errors.Is(err, errPermanent) || attempt == s.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return receipt, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+	return receipt, lastErr
+}
+
+// post makes a single gateway request and returns the provider response as reference.
+func (s *HTTPSender) post(ctx context.Context, mobile, text string) (string, error) {
+	values := map[string]string{
+		"{mobile}":      mobile,
+		"{message}":     text,
+		"{template_id}": s.TemplateID,
+		"{sender_id}":   s.SenderID,
+	}
+
+	var body io.Reader
+	if s.Method == http.MethodPost && s.BodyTemplate != "" {
+		escape := func(v string) string { return v }
+		switch {
+		case s.ContentType == "application/x-www-form-urlencoded":
+			escape = url.QueryEscape
+		case strings.Contains(s.ContentType, "json"):
+			escape = jsonEscape
+		}
+		body = strings.NewReader(expand(s.BodyTemplate, values, escape))
+	}
+
+	// The URL can hold the gateway key and, with a GET template, the code,
+	// so errors carrying it are stripped before they are logged or stored.
+	req, err := http.NewRequestWithContext(ctx, s.Method, expand(s.URLTemplate, values, url.QueryEscape), body)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid gateway URL", errPermanent)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", s.ContentType)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("sms gateway: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	ref := strings.TrimSpace(string(respBody))
+	if len(ref) > 200 {
+		ref = ref[:200]
+	}
+
+	switch {
+	case resp.StatusCode >= 500:
+		return ref, fmt.Errorf("gateway returned %d", resp.StatusCode)
+	case resp.StatusCode >= 300:
+		return ref, fmt.Errorf("%w: status %d", errPermanent, resp.StatusCode)
+	case s.SuccessMatch != "" && !strings.Contains(string(respBody), s.SuccessMatch):
+		return ref, fmt.Errorf("%w: unexpected response %q", errPermanent, ref)
+	}
+	return ref, nil
+}
+
+// jsonEscape escapes v for use inside a JSON string literal of a body template.
+func jsonEscape(v string) string {
+	b, _ := json.Marshal(v)
+	return string(b[1 : len(b)-1])
+}
+
+// expand replaces the placeholders in tmpl with escaped values.
+func expand(tmpl string, values map[string]string, escape func(string) string) string {
+	pairs := make([]string, 0, len(values)*2)
+	for placeholder, value := range values {
+		pairs = append(pairs, placeholder, escape(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
@@ -0,0 +1,99 @@
+package otp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 appendix B test vectors.
+var rfc6238Secret = b32.EncodeToString([]byte("12345678901234567890"))
+
+// rfc6238Vectors are the SHA-1 vectors of RFC 6238 appendix B. The RFC
+// prints 8 digits; a 6-digit code is the last 6 of them.
+var rfc6238Vectors = []struct {
+	unix int64
+	step int64
+	code string
+}{
+	{59, 0x1, "287082"},                 // 94287082
+	{1111111109, 0x23523EC, "081804"},   // 07081804
+	{1111111111, 0x23523ED, "050471"},   // 14050471
+	{1234567890, 0x273EF07, "005924"},   // 89005924
+	{2000000000, 0x3F940AA, "279037"},   // 69279037
+	{20000000000, 0x27BC86AA, "353130"}, // 65353130
+}
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	key := []byte("12345678901234567890")
+	for _, v := range rfc6238Vectors {
+		at := time.Unix(v.unix, 0)
+		if got := TOTPCounter(at); got != v.step {
+			t.Errorf("TOTPCounter(%d) = %#x, want %#x", v.unix, got, v.step)
+		}
+		if got := totpCode(key, v.step); got != v.code {
+			t.Errorf("totpCode at %d = %s, want %s", v.unix, got, v.code)
+		}
+		step, ok := ValidateTOTP(rfc6238Secret, v.code, at, -1)
+		if !ok || step != v.step {
+			t.Errorf("ValidateTOTP(%s at %d) = %#x, %v, want %#x, true", v.code, v.unix, step, ok, v.step)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	// 1111111111 is step 0x23523ED; the step before it is the 1111111109 vector
+	at := time.Unix(1111111111, 0)
+	key := []byte("12345678901234567890")
+	prev, next, later := "081804", totpCode(key, 0x23523EE), totpCode(key, 0x23523EF)
+	earlier := totpCode(key, 0x23523EB)
+
+	tests := []struct {
+		name        string
+		secret      string
+		code        string
+		lastCounter int64
+		wantStep    int64
+		wantOK      bool
+	}{
+		{"current step", rfc6238Secret, "050471", -1, 0x23523ED, true},
+		{"one step behind", rfc6238Secret, prev, -1, 0x23523EC, true},
+		{"one step ahead", rfc6238Secret, next, -1, 0x23523EE, true},
+		{"two steps behind", rfc6238Secret, earlier, -1, 0, false},
+		{"two steps ahead", rfc6238Secret, later, -1, 0, false},
+		{"replay of the last step", rfc6238Secret, "050471", 0x23523ED, 0, false},
+		{"step before the last one", rfc6238Secret, prev, 0x23523ED, 0, false},
+		{"step after the last one", rfc6238Secret, next, 0x23523ED, 0x23523EE, true},
+		{"wrong code", rfc6238Secret, "123456", -1, 0, false},
+		{"short code", rfc6238Secret, "50471", -1, 0, false},
+		{"8-digit RFC code", rfc6238Secret, "14050471", -1, 0, false},
+		{"lower-case secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "050471", -1, 0x23523ED, true},
+		{"secret not base32", "not base32!", "050471", -1, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, at, tt.lastCounter)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP = %#x, %v, want %#x, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	u, err := url.Parse(TOTPURI("HR Portal", "alice", rfc6238Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/HR Portal:alice" {
+		t.Errorf("URI = %s, want otpauth://totp/HR Portal:alice", u)
+	}
+	q := u.Query()
+	for name, want := range map[string]string{
+		"secret": rfc6238Secret, "issuer": "HR Portal", "algorithm": "SHA1", "digits": "6", "period": "30",
+	} {
+		if got := q.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
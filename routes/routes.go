@@ -8,58 +8,100 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 09-07-2025
+// Last Modified Date: 17-10-2026
 package routes
 
 import (
 	"Hrmodule/auth"
+	"Hrmodule/config"
 	controllerscommon "Hrmodule/controllers/common"
 	controllerslogin "Hrmodule/controllers/login"
-	"fmt"
 	"net/http"
 
 	"github.com/rs/cors"
 )
 
-// Registerroutes sets up the HTTPS server with CORS support,
-func Registerroutes() {
+// routePolicies is the per-route policy table: the permission a route
+// requires in addition to a valid JWT. Routes not listed only need a JWT.
+// Checks that depend on the request body (for example reading another
+// user's session) are done in the handlers.
+var routePolicies = map[string]string{
+	"/Inboxactivity": auth.PermWorkflowAct,
+	"/LoginUnlock":   auth.PermLoginUnlock,
+	"/Impersonate":   auth.PermImpersonate,
+	"/ApiKeyIssue":   auth.PermAPIKeyManage,
+	"/ApiKeyRotate":  auth.PermAPIKeyManage,
+	"/ApiKeyRevoke":  auth.PermAPIKeyManage,
+	"/HealthReport":  auth.PermHealthView,
+}
+
+// protected wraps h with JwtMiddleware and the route's policy, if any.
+func protected(path string, h http.HandlerFunc) http.Handler {
+	var handler http.Handler = h
+	if permission, ok := routePolicies[path]; ok {
+		handler = auth.RequirePermission(permission)(handler)
+	}
+	return auth.JwtMiddleware(handler)
+}
+
+// Registerroutes registers the API routes and returns them wrapped with
+// CORS support. The server itself is started by Serve.
+func Registerroutes(cfg *config.Config) http.Handler {
 	// Create a new ServeMux router
 	router := http.NewServeMux()
 
 	// Register your API routes  Login api
 	router.Handle("/HRldap", (http.HandlerFunc(controllerslogin.HandleLDAPAuth)))
-	router.Handle("/Loginotp", (http.HandlerFunc(controllerslogin.InsertOTPHandler)))
-	router.Handle("/Loginotpupdate", (http.HandlerFunc(controllerslogin.ValidateOTPHandler)))
-	router.Handle("/Loginotpresend", (http.HandlerFunc(controllerslogin.InsertOTPresendHandler)))
-	router.Handle("/SessionTimeout", auth.JwtMiddleware(http.HandlerFunc(controllerslogin.SessionTimeoutHandler)))
-	router.Handle("/Sessiondata", auth.JwtMiddleware(http.HandlerFunc(controllerslogin.SessionData)))
+	router.Handle("/Loginotp", auth.PreAuthMiddleware(http.HandlerFunc(controllerslogin.InsertOTPHandler)))
+	router.Handle("/Loginotpupdate", auth.PreAuthMiddleware(http.HandlerFunc(controllerslogin.ValidateOTPHandler)))
+	router.Handle("/Loginotpresend", auth.PreAuthMiddleware(http.HandlerFunc(controllerslogin.InsertOTPresendHandler)))
+	router.Handle("/TokenRefresh", (http.HandlerFunc(controllerslogin.TokenRefreshHandler)))
+	router.Handle("/SessionTimeout", protected("/SessionTimeout", controllerslogin.SessionTimeoutHandler))
+	router.Handle("/Sessiondata", protected("/Sessiondata", controllerslogin.SessionData))
+	router.Handle("/LoginUnlock", protected("/LoginUnlock", controllerslogin.LoginUnlockHandler))
+	router.Handle("/MySessions", protected("/MySessions", controllerslogin.MySessionsHandler))
+	router.Handle("/MySessionRevoke", protected("/MySessionRevoke", controllerslogin.MySessionRevokeHandler))
+	router.Handle("/Impersonate", protected("/Impersonate", controllerslogin.ImpersonateHandler))
 
-	//Role api
-	router.Handle("/Defaultrole", auth.JwtMiddleware(http.HandlerFunc(controllerscommon.DefaultRoleName)))
-	router.Handle("/TaskInbox", auth.JwtMiddleware(http.HandlerFunc(controllerscommon.InboxTasksRole)))
-	router.Handle("/Statusmaster", auth.JwtMiddleware(http.HandlerFunc(controllerscommon.StatusMaster)))
-	router.Handle("/Inboxactivity", auth.JwtMiddleware(http.HandlerFunc(controllerscommon.NOCUpdateHandler)))
+	// Authenticator app (TOTP) second factor
+	router.Handle("/TotpEnrol", protected("/TotpEnrol", controllerslogin.TotpEnrolHandler))
+	router.Handle("/TotpConfirm", protected("/TotpConfirm", controllerslogin.TotpConfirmHandler))
+	router.Handle("/TotpVerify", auth.PreAuthMiddleware(http.HandlerFunc(controllerslogin.TotpVerifyHandler)))
+	router.Handle("/MfaPreference", protected("/MfaPreference", controllerslogin.MfaPreferenceHandler))
 
-	// CORS configuration
-	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"}, // Use specific origin(s) in production
-		AllowedMethods:   []string{"POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
-		AllowCredentials: true,
-	})
+	// Public verification keys for services validating our JWTs
+	router.Handle("/.well-known/jwks.json", http.HandlerFunc(auth.JWKSHandler))
 
-	// Apply CORS middleware to the router
-	handler := c.Handler(router)
+	//Role api
+	router.Handle("/Defaultrole", protected("/Defaultrole", controllerscommon.DefaultRoleName))
+	router.Handle("/TaskInbox", protected("/TaskInbox", controllerscommon.InboxTasksRole))
+	router.Handle("/Statusmaster", protected("/Statusmaster", controllerscommon.StatusMaster))
+	router.Handle("/Inboxactivity", protected("/Inboxactivity", controllerscommon.NOCUpdateHandler))
 
-	fmt.Println("Server starting on port 5000")
+	// API key administration
+	router.Handle("/ApiKeyIssue", protected("/ApiKeyIssue", controllerscommon.ApiKeyIssueHandler))
+	router.Handle("/ApiKeyRotate", protected("/ApiKeyRotate", controllerscommon.ApiKeyRotateHandler))
+	router.Handle("/ApiKeyRevoke", protected("/ApiKeyRevoke", controllerscommon.ApiKeyRevokeHandler))
 
-	// TLS certificate and key
-	certFile := "certificate.pem"
-	keyFile := "key.pem"
+	// Health: probes are public, the dependency report is admin-only
+	router.Handle("/healthz", http.HandlerFunc(controllerscommon.HealthzHandler))
+	router.Handle("/readyz", http.HandlerFunc(controllerscommon.ReadyzHandler))
+	router.Handle("/HealthReport", protected("/HealthReport", controllerscommon.HealthReportHandler))
 
-	// Start the HTTPS server with CORS-enabled handler
-	err := http.ListenAndServeTLS(":5000", certFile, keyFile, handler)
-	if err != nil {
-		fmt.Println("Server error:", err)
+	// CORS configuration: only the listed origins (CORS_ALLOWED_ORIGINS),
+	// since credentials are allowed
+	opts := cors.Options{
+		AllowedOrigins:   cfg.Server.CORSOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Device-Label"},
+		AllowCredentials: true,
 	}
+	if len(opts.AllowedOrigins) == 0 {
+		// cors treats an empty list as "*"; refuse every origin instead
+		opts.AllowOriginFunc = func(string) bool { return false }
+	}
+	c := cors.New(opts)
+
+	// Apply CORS middleware to the router
+	return c.Handler(router)
 }
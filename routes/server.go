@@ -0,0 +1,113 @@
+// Package routes runs the HTTP servers and shuts them down gracefully.
+//
+// The servers have read, write and idle timeouts so a slow or idle client
+// cannot hold a connection forever. When ctx is cancelled (main cancels it
+// on SIGTERM or SIGINT) the listeners are closed and in-flight requests get
+// SERVER_SHUTDOWN_TIMEOUT to finish before their connections are dropped.
+//
+// With SERVER_BEHIND_PROXY the main server speaks plain HTTP and expects a
+// reverse proxy to terminate TLS; otherwise it serves HTTPS with the
+// certificate of a CertManager. MTLS_PORT adds an HTTPS server for
+// internal services that authenticate with client certificates.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package routes
+
+import (
+	"Hrmodule/config"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// NewServer returns the HTTP server for handler on port with the SERVER_* limits.
+func NewServer(cfg *config.Config, port string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + port,
+		Handler:           handler,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+}
+
+// NewServers returns the main server and, when MTLS_PORT is set, the
+// mutual-TLS server, both serving handler. certs may be nil only when
+// neither serves TLS.
+func NewServers(cfg *config.Config, handler http.Handler, certs *CertManager) ([]*http.Server, error) {
+	srv := NewServer(cfg, cfg.Server.Port, handler)
+	if !cfg.Server.BehindProxy {
+		srv.TLSConfig = serverTLSConfig(certs)
+	}
+	servers := []*http.Server{srv}
+
+	if cfg.Server.MTLSPort != "" {
+		tlsConfig, err := mutualTLSConfig(cfg, certs)
+		if err != nil {
+			return nil, err
+		}
+		mtls := NewServer(cfg, cfg.Server.MTLSPort, handler)
+		mtls.TLSConfig = tlsConfig
+		servers = append(servers, mtls)
+	}
+	return servers, nil
+}
+
+// Serve runs servers until ctx is cancelled or one of them fails, then
+// shuts them all down gracefully. It returns the error that stopped a
+// listener, or the first shutdown error.
+func Serve(ctx context.Context, cfg *config.Config, servers ...*http.Server) error {
+	serveErr := make(chan error, len(servers))
+	for _, srv := range servers {
+		go func(srv *http.Server) {
+			if srv.TLSConfig == nil {
+				log.Printf("Server starting on %s (plain HTTP behind proxy)", srv.Addr)
+				serveErr <- srv.ListenAndServe()
+				return
+			}
+			if srv.TLSConfig.ClientCAs != nil {
+				log.Printf("Server starting on %s (HTTPS with client certificates)", srv.Addr)
+			} else {
+				log.Printf("Server starting on %s (HTTPS)", srv.Addr)
+			}
+			serveErr <- srv.ListenAndServeTLS("", "")
+		}(srv)
+	}
+
+	var err error
+	running := len(servers)
+	select {
+	case err = <-serveErr:
+		running--
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, waiting up to %s for in-flight requests", cfg.Server.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	for _, srv := range servers {
+		if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil {
+			srv.Close()
+			if err == nil {
+				err = shutdownErr
+			}
+		}
+	}
+	for ; running > 0; running-- {
+		if serveErr := <-serveErr; err == nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			err = serveErr
+		}
+	}
+	return err
+}
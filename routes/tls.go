@@ -0,0 +1,165 @@
+// Package routes serves the server certificate and sets the TLS policy.
+//
+// CertManager keeps the certificate from TLS_CERT_FILE and TLS_KEY_FILE in
+// memory and hands it to every handshake through tls.Config.GetCertificate.
+// Watch checks the files every TLS_RELOAD_INTERVAL and loads a renewed pair
+// without a restart; if the new pair cannot be loaded (for example the
+// certificate was replaced before the key), the current one stays in use
+// and the next check tries again.
+//
+// Every listener requires TLS 1.2 or later. For TLS 1.2 only forward-secret
+// AEAD cipher suites are offered; TLS 1.3 suites are fixed by Go.
+//
+// --- Creator's Info ---
+//
+// Creator: Sridharan
+//
+// Created On: 17-10-2026
+//
+// Last Modified By: Sridharan
+//
+// Last Modified Date: 17-10-2026
+package routes
+
+import (
+	"Hrmodule/config"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsCipherSuites are the TLS 1.2 cipher suites offered, strongest first.
+var tlsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+}
+
+// CertManager holds the server certificate and reloads it when its files change.
+type CertManager struct {
+	certFile, keyFile string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modTimes [2]time.Time // of certFile and keyFile when cert was loaded
+}
+
+// NewCertManager loads the certificate pair. It fails if the pair cannot be loaded.
+func NewCertManager(certFile, keyFile string) (*CertManager, error) {
+	m := &CertManager{certFile: certFile, keyFile: keyFile}
+	if _, err := m.reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GetCertificate returns the current certificate; it is set as
+// tls.Config.GetCertificate.
+func (m *CertManager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cert, nil
+}
+
+// Leaf returns the parsed current certificate.
+func (m *CertManager) Leaf() *x509.Certificate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cert.Leaf
+}
+
+// reload loads the pair if either file changed since the last load and
+// reports whether it did.
+func (m *CertManager) reload() (bool, error) {
+	var modTimes [2]time.Time
+	for i, file := range []string{m.certFile, m.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, fmt.Errorf("TLS certificate: %v", err)
+		}
+		modTimes[i] = info.ModTime()
+	}
+
+	m.mu.RLock()
+	unchanged := m.cert != nil && modTimes == m.modTimes
+	m.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(m.certFile, m.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("TLS certificate: %v", err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return false, fmt.Errorf("TLS certificate: %v", err)
+		}
+	}
+
+	m.mu.Lock()
+	m.cert = &cert
+	m.modTimes = modTimes
+	m.mu.Unlock()
+	return true, nil
+}
+
+// Watch checks the certificate files every interval until ctx is cancelled.
+func (m *CertManager) Watch(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				reloaded, err := m.reload()
+				if err != nil {
+					log.Printf("TLS certificate reload failed, keeping the current one: %v", err)
+				}
+				if reloaded {
+					log.Printf("TLS certificate reloaded, valid until %s", m.Leaf().NotAfter.Format(time.RFC3339))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// serverTLSConfig returns the TLS policy of every listener, serving the
+// certificate of certs.
+func serverTLSConfig(certs *CertManager) *tls.Config {
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CipherSuites:     tlsCipherSuites,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+		GetCertificate:   certs.GetCertificate,
+	}
+}
+
+// mutualTLSConfig is serverTLSConfig that also requires a client
+// certificate signed by one of the CAs in MTLS_CLIENT_CA_FILE.
+func mutualTLSConfig(cfg *config.Config, certs *CertManager) (*tls.Config, error) {
+	pem, err := os.ReadFile(cfg.Server.MTLSClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("MTLS_CLIENT_CA_FILE: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("MTLS_CLIENT_CA_FILE: no PEM certificates found")
+	}
+
+	tlsConfig := serverTLSConfig(certs)
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	tlsConfig.ClientCAs = pool
+	return tlsConfig, nil
+}
@@ -1,5 +1,5 @@
 // Package utils provides utility functions including AES-GCM
-// encryption for secure response encoding.
+// encryption for secure response encoding and for secrets stored at rest.
 //
 // --- Creator's Info ---
 //
@@ -9,40 +9,46 @@
 //
 // Last Modified By: Sridharan
 //
-// Last Modified Date: 09-07-2025
+// Last Modified Date: 17-10-2026
 package utils
 
 import (
+	"Hrmodule/config"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
 	"encoding/base64"
-	"math/rand"
-	"os"
-	"time"
-
-	"github.com/joho/godotenv"
+	"errors"
 )
 
 var secretKey []byte
 
-// init loads the ENCRYPTION_KEY from the .env file into memory.
-// It panics if the key is not found or is not exactly 32 bytes,
-// which is required for AES-256 encryption.
-func init() {
-	err := godotenv.Load()
+// Configure sets the AES-256 key from ENCRYPTION_KEY, which config.Load
+// has checked to be exactly 32 bytes.
+func Configure(cfg *config.Config) error {
+	secretKey = []byte(cfg.Security.EncryptionKey)
+	return nil
+}
+
+// SelfTest encrypts and decrypts a sample to check that the key is loaded
+// and usable. The health checks call it.
+func SelfTest() error {
+	if len(secretKey) == 0 {
+		return errors.New("encryption key not loaded")
+	}
+	sample := []byte("self-test")
+	encrypted, err := Encrypt(sample)
 	if err != nil {
-		panic("Error loading .env file")
+		return err
 	}
-
-	key := os.Getenv("ENCRYPTION_KEY")
-	if key == "" {
-		panic("ENCRYPTION_KEY not set")
+	decrypted, err := Decrypt(encrypted)
+	if err != nil {
+		return err
 	}
-	if len(key) != 32 {
-		panic("ENCRYPTION_KEY must be 32 bytes")
+	if string(decrypted) != string(sample) {
+		return errors.New("encryption round trip mismatch")
 	}
-
-	secretKey = []byte(key)
+	return nil
 }
 
 // Encrypt takes plainText as input and returns an encrypted string
@@ -50,7 +56,6 @@ func init() {
 // that includes the nonce used for encryption.
 func Encrypt(plainText []byte) (string, error) {
 	// Generate random nonce
-	rand.Seed(time.Now().UnixNano())
 	nonce := make([]byte, 12) // AES-GCM standard nonce size
 	_, err := rand.Read(nonce)
 	if err != nil {
@@ -75,3 +80,28 @@ func Encrypt(plainText []byte) (string, error) {
 	// Return as base64 string
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
+
+// Decrypt reverses Encrypt: it decodes the base64 string, splits off the
+// nonce and opens the AES-GCM ciphertext.
+func Decrypt(encoded string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(secretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aesGCM.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := data[:aesGCM.NonceSize()], data[aesGCM.NonceSize():]
+	return aesGCM.Open(nil, nonce, ciphertext, nil)
+}